@@ -48,4 +48,5 @@ template ProveMerkle(N) {
     root <== current;
 }
 
+// N is the length of the path: 3 + 2 * depth for go/dynamic-merkle proofs, see its README
 component main = ProveMerkle(2);
\ No newline at end of file
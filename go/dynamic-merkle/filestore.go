@@ -0,0 +1,280 @@
+package dynamic_merkle
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	opPutNode    = "node"
+	opDeleteNode = "delete_node"
+	opPutRoot    = "root"
+	opDeleteRoot = "delete_root"
+	opPutHasher  = "hasher"
+)
+
+// fileRecord is one line of FileStore log
+type fileRecord struct {
+	Op      string      `json:"op"`
+	ID      []byte      `json:"id,omitempty"`
+	Node    *StoredNode `json:"node,omitempty"`
+	Version uint64      `json:"version,omitempty"`
+}
+
+// FileStore is the NodeStore persisted in the append-only file of JSON records.
+// The file is replayed into memory on open. Compact rewrites it with the current state only.
+// Root records are synced to disk, so every committed version survives the crash.
+// Records are written and applied to memory under the same lock as Compact takes, so it does not lose them.
+type FileStore struct {
+	*MemoryStore
+
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// Implements NodeStore and Compactor
+var (
+	_ NodeStore = &FileStore{}
+	_ Compactor = &FileStore{}
+)
+
+var (
+	// ErrCorruptedFile is returned on open if the record before the last one is not valid JSON or any record is invalid
+	ErrCorruptedFile = errors.New("store file is corrupted")
+	ErrInvalidRecord = errors.New("node and hasher should have id, node should have body, root version should be positive")
+)
+
+// OpenFileStore opens or creates the store file. The partially written last record is truncated.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+
+	size, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	// new records should not be appended to the partial one
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	s.file = file
+	return s, nil
+}
+
+// replay applies file records to the memory store and returns the size of valid records
+func (s *FileStore) replay() (int64, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	var size int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// the last record may be partially written before the crash
+			return size, nil
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		var r fileRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			// the partial record is followed by the newline only if it is not the last one
+			if _, err := reader.Peek(1); err == io.EOF {
+				return size, nil
+			}
+
+			return 0, ErrCorruptedFile
+		}
+
+		if !r.valid() {
+			return 0, ErrCorruptedFile
+		}
+
+		if err := s.applyRecord(&r); err != nil {
+			return 0, err
+		}
+
+		size += int64(len(line))
+	}
+}
+
+func (s *FileStore) PutNode(id []byte, node *StoredNode) error {
+	return s.apply(&fileRecord{Op: opPutNode, ID: id, Node: node}, false)
+}
+
+func (s *FileStore) DeleteNode(id []byte) error {
+	return s.apply(&fileRecord{Op: opDeleteNode, ID: id}, false)
+}
+
+func (s *FileStore) PutRoot(version uint64, id []byte) error {
+	return s.apply(&fileRecord{Op: opPutRoot, ID: id, Version: version}, true)
+}
+
+func (s *FileStore) DeleteRoot(version uint64) error {
+	return s.apply(&fileRecord{Op: opDeleteRoot, Version: version}, false)
+}
+
+func (s *FileStore) PutHasher(id []byte) error {
+	return s.apply(&fileRecord{Op: opPutHasher, ID: id}, true)
+}
+
+// Compact rewrites the file with the current nodes, roots and hasher id
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := s.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+
+	if err := s.writeState(enc); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	return err
+}
+
+// Close closes the file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *FileStore) writeState(enc *json.Encoder) error {
+	s.MemoryStore.mu.RLock()
+	defer s.MemoryStore.mu.RUnlock()
+
+	for id, node := range s.nodes {
+		if err := enc.Encode(&fileRecord{Op: opPutNode, ID: []byte(id), Node: node}); err != nil {
+			return err
+		}
+	}
+
+	for version, id := range s.roots {
+		if err := enc.Encode(&fileRecord{Op: opPutRoot, ID: id, Version: version}); err != nil {
+			return err
+		}
+	}
+
+	if s.hasher != nil {
+		return enc.Encode(&fileRecord{Op: opPutHasher, ID: s.hasher})
+	}
+
+	return nil
+}
+
+// apply writes the record and applies it to the memory store under one lock,
+// so Compact can not take the state without the written record
+func (s *FileStore) apply(r *fileRecord, sync bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !r.valid() {
+		return ErrInvalidRecord
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	if sync {
+		if err := s.file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	return s.applyRecord(r)
+}
+
+// applyRecord applies the valid record to the memory store
+func (s *FileStore) applyRecord(r *fileRecord) error {
+	switch r.Op {
+	case opPutNode:
+		return s.MemoryStore.PutNode(r.ID, r.Node)
+	case opDeleteNode:
+		return s.MemoryStore.DeleteNode(r.ID)
+	case opPutRoot:
+		return s.MemoryStore.PutRoot(r.Version, r.ID)
+	case opPutHasher:
+		return s.MemoryStore.PutHasher(r.ID)
+	default:
+		return s.MemoryStore.DeleteRoot(r.Version)
+	}
+}
+
+// valid returns false for records that are not written by FileStore: nodes or hasher without id, nodes without body,
+// roots of version 0 and unknown operations
+func (r *fileRecord) valid() bool {
+	switch r.Op {
+	case opPutNode:
+		return r.Node != nil && len(r.ID) > 0
+	case opPutHasher:
+		return len(r.ID) > 0
+	case opPutRoot:
+		return r.Version > 0
+	case opDeleteNode, opDeleteRoot:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,187 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestFileStoreRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "treap.log")
+
+	commit := func(store *FileStore, key []byte) []byte {
+		versioned, err := NewVersioned(store, nil)
+		if err != nil {
+			panic(err)
+		}
+
+		if err := versioned.Insert(key, 1); err != nil {
+			panic(err)
+		}
+
+		if _, err := versioned.Commit(); err != nil {
+			panic(err)
+		}
+
+		root, err := versioned.MerkleRoot()
+		if err != nil {
+			panic(err)
+		}
+
+		return root
+	}
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	roots := [][]byte{commit(store, crypto.Keccak256([]byte{1}))}
+
+	if err := store.Close(); err != nil {
+		panic(err)
+	}
+
+	// crash in the middle of the record
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err := file.WriteString(`{"op":"root","id":"`); err != nil {
+		panic(err)
+	}
+
+	file.Close()
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	roots = append(roots, commit(store, crypto.Keccak256([]byte{2})), commit(store, crypto.Keccak256([]byte{3})))
+
+	if err := store.Close(); err != nil {
+		panic(err)
+	}
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	versioned, err := NewVersioned(store, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	if versioned.Version() != 3 {
+		panic("versions written after the partial record are lost")
+	}
+
+	for i, root := range roots {
+		res, err := versioned.MerkleRootAt(uint64(i + 1))
+		if err != nil || !bytes.Equal(res, root) {
+			panic("invalid root after recovery")
+		}
+	}
+
+	if err := store.Close(); err != nil {
+		panic(err)
+	}
+
+	// corrupted record in the middle of the file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	data[1] = '#'
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		panic(err)
+	}
+
+	if _, err := OpenFileStore(path); err != ErrCorruptedFile {
+		panic("corrupted file is opened")
+	}
+}
+
+func TestFileStoreInvalidRecords(t *testing.T) {
+	records := []string{
+		`{"op":"node","id":"AQ=="}`,
+		`{"op":"node","node":{"key":"AQ==","priority":1,"merkle_hash":"AQ=="}}`,
+		`{"op":"root","id":"AQ=="}`,
+		`{"op":"unknown"}`,
+	}
+
+	for i, r := range records {
+		path := filepath.Join(t.TempDir(), "treap.log")
+		if err := os.WriteFile(path, []byte(r+"\n"), 0o600); err != nil {
+			panic(err)
+		}
+
+		if _, err := OpenFileStore(path); err != ErrCorruptedFile {
+			panic(fmt.Sprintf("invalid record %d is accepted", i))
+		}
+	}
+}
+
+func TestFileStoreConcurrentCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "treap.log")
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				id := crypto.Keccak256([]byte{byte(w), byte(i), byte(i >> 8)})
+				if err := store.PutNode(id, &StoredNode{Key: id, MerkleHash: id}); err != nil {
+					panic(err)
+				}
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			if err := store.Compact(); err != nil {
+				panic(err)
+			}
+		}
+	}()
+
+	wg.Wait()
+	<-done
+
+	if err := store.Close(); err != nil {
+		panic(err)
+	}
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	defer store.Close()
+
+	if ids, _ := store.NodeIDs(); len(ids) != 4*1000 {
+		panic("nodes written during compaction are lost")
+	}
+
+	if err := store.PutNode(nil, &StoredNode{}); err != ErrInvalidRecord {
+		panic("invalid node is written")
+	}
+}
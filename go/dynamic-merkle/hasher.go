@@ -0,0 +1,98 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// Hasher hashes two non-empty values of the tree
+type Hasher interface {
+	Hash(a, b []byte) []byte
+}
+
+// PositionalHasher is the Hasher that binds positions of values: Hash(a, b) != Hash(b, a) and values are canonical,
+// so they can not be moved between arguments. Only such hashers commit to children positions, so non-membership
+// proofs are verified only with them.
+type PositionalHasher interface {
+	Hasher
+	Positional()
+}
+
+// emptyChild replaces the absent child of the node in hashChildren: keccak256("dynamic_merkle.empty_child")
+// with the top 3 bits cleared, so it is the BN254 scalar field element too
+var emptyChild = func() []byte {
+	res := crypto.Keccak256([]byte("dynamic_merkle.empty_child"))
+	res[0] &= 0x1f
+	return res
+}()
+
+// ValueChecker is the Hasher that accepts only some values. Verifiers check untrusted proof values with it
+// and reject the proof instead of hashing invalid values.
+type ValueChecker interface {
+	Hasher
+	ValidValue(v []byte) bool
+}
+
+// validValues returns false if h is ValueChecker and any value is not valid for it
+func validValues(h Hasher, values ...[]byte) bool {
+	c, ok := h.(ValueChecker)
+	if !ok {
+		return true
+	}
+
+	for _, v := range values {
+		if !c.ValidValue(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isPositional(h Hasher) bool {
+	_, ok := h.(PositionalHasher)
+	return ok
+}
+
+// Keccak256Hasher is the default hasher: keccak256 of sorted values.
+// Values are sorted, so the hash does not depend on children positions and non-membership proofs are not supported.
+type Keccak256Hasher struct{}
+
+var _ Hasher = Keccak256Hasher{}
+
+func (Keccak256Hasher) Hash(a, b []byte) []byte {
+	if bytes.Compare(a, b) < 0 {
+		return crypto.Keccak256(a, b)
+	}
+
+	return crypto.Keccak256(b, a)
+}
+
+// PoseidonHasher hashes values with Poseidon(2) of circomlib over BN254 scalar field.
+// Values are big-endian field elements, result is 32 bytes big-endian. Values are not sorted,
+// so the path can be checked with `circuits/merkle/merkle.circom` built for its depth (see Proof.CircuitInput).
+// Keys should be less than the field modulus: Hash panics on other values, verifiers reject them with ValidValue.
+type PoseidonHasher struct{}
+
+var _ PositionalHasher = PoseidonHasher{}
+var _ ValueChecker = PoseidonHasher{}
+
+func (PoseidonHasher) Positional() {}
+
+// ValidValue returns true if the value is less than the field modulus
+func (PoseidonHasher) ValidValue(v []byte) bool {
+	return utils.CheckBigIntInField(new(big.Int).SetBytes(v))
+}
+
+func (PoseidonHasher) Hash(a, b []byte) []byte {
+	res, err := poseidon.Hash([]*big.Int{new(big.Int).SetBytes(a), new(big.Int).SetBytes(b)})
+	if err != nil {
+		panic(err)
+	}
+
+	return res.FillBytes(make([]byte, 32))
+}
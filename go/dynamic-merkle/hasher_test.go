@@ -0,0 +1,141 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// foldCircuit computes the root as ProveMerkle template does
+func foldCircuit(input *CircuitInput) []byte {
+	h := PoseidonHasher{}
+
+	value := func(s string) []byte {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			panic("invalid circuit value")
+		}
+
+		return v.Bytes()
+	}
+
+	current := value(input.In[0])
+	for i := 1; i < len(input.In); i++ {
+		if input.Indices[i] == "0" {
+			current = h.Hash(current, value(input.In[i]))
+		} else {
+			current = h.Hash(value(input.In[i]), current)
+		}
+	}
+
+	return current
+}
+
+func TestPoseidonHasher(t *testing.T) {
+	// circomlib poseidon([1, 2]) test vector
+	expected, _ := new(big.Int).SetString("7853200120776062878684798364095072458815029376092732009249414926327459813530", 10)
+	if new(big.Int).SetBytes(PoseidonHasher{}.Hash([]byte{1}, []byte{2})).Cmp(expected) != 0 {
+		panic("poseidon hash is not compatible with circomlib")
+	}
+
+	tree := NewWithHasher(PoseidonHasher{})
+
+	keys := make([][]byte, 0, 50)
+	for i := 1; i <= 50; i++ {
+		key := big.NewInt(int64(i * 1000)).Bytes()
+		keys = append(keys, key)
+		tree.Insert(key, rand.Uint64())
+	}
+
+	for _, key := range keys {
+		proof := tree.MerklePath(key)
+		if !VerifyProofWithHasher(PoseidonHasher{}, tree.MerkleRoot(), key, proof) {
+			panic("proof is invalid")
+		}
+
+		if VerifyProof(tree.MerkleRoot(), key, proof) {
+			panic("proof is valid with another hasher")
+		}
+
+		if new(big.Int).SetBytes(foldCircuit(proof.CircuitInput(PoseidonHasher{}, key))).Cmp(new(big.Int).SetBytes(tree.MerkleRoot())) != 0 {
+			panic("circuit input does not lead to the root")
+		}
+	}
+
+	key := big.NewInt(1500).Bytes()
+	if !VerifyNonMembershipWithHasher(PoseidonHasher{}, tree.MerkleRoot(), key, tree.NonMembershipProof(key)) {
+		panic("non-membership proof is invalid")
+	}
+}
+
+func TestPoseidonInvalidValues(t *testing.T) {
+	h := PoseidonHasher{}
+	invalid := bytes.Repeat([]byte{0xff}, 32)
+
+	if h.ValidValue(invalid) || !h.ValidValue([]byte{1}) {
+		panic("invalid value check")
+	}
+
+	// increasing priorities: 10000 is the root and 1000 is the deepest leaf
+	tree := NewWithHasher(h)
+	for i := 1; i <= 10; i++ {
+		tree.Insert(big.NewInt(int64(i*1000)).Bytes(), uint64(i))
+	}
+
+	key := big.NewInt(1000).Bytes()
+	root := tree.MerkleRoot()
+
+	proof := tree.MerklePath(key)
+
+	proof.Path[0].Sibling = invalid
+	if VerifyProofWithHasher(h, root, key, proof) {
+		panic("proof with invalid sibling is valid")
+	}
+
+	if VerifyProofWithHasher(h, root, invalid, tree.MerklePath(key)) {
+		panic("proof with invalid key is valid")
+	}
+
+	absent := big.NewInt(1500).Bytes()
+	nm := tree.NonMembershipProof(absent)
+	nm.Predecessor.Left = invalid
+	if VerifyNonMembershipWithHasher(h, root, absent, nm) {
+		panic("non-membership proof with invalid value is valid")
+	}
+
+	multi := tree.MultiProof([][]byte{key})
+	multi.Nodes[0].Key = invalid
+	if VerifyMultiProofWithHasher(h, root, [][]byte{key}, multi) {
+		panic("multiproof with invalid key is valid")
+	}
+
+	// the root proof contains the hash of its left subtree
+	rootKey := big.NewInt(10000).Bytes()
+	multi = tree.MultiProof([][]byte{rootKey})
+	multi.Hashes[0] = invalid
+	if VerifyMultiProofWithHasher(h, root, [][]byte{rootKey}, multi) {
+		panic("multiproof with invalid hash is valid")
+	}
+
+	sparse := NewSparseWithHasher(h)
+	sparseKey := make([]byte, 32)
+	if err := sparse.Set(sparseKey, []byte{1}); err != nil {
+		panic(err)
+	}
+
+	sparseProof, err := sparse.Prove(sparseKey)
+	if err != nil {
+		panic(err)
+	}
+
+	if VerifySparseProofWithHasher(h, sparse.MerkleRoot(), sparseKey, invalid, sparseProof) {
+		panic("sparse proof with invalid value is valid")
+	}
+
+	sparseProof.Bitmap[31] |= 1
+	sparseProof.Siblings = append(sparseProof.Siblings, invalid)
+	if VerifySparseProofWithHasher(h, sparse.MerkleRoot(), sparseKey, []byte{1}, sparseProof) {
+		panic("sparse proof with invalid sibling is valid")
+	}
+}
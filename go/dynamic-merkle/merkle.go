@@ -1,11 +1,13 @@
-package main
+package dynamic_merkle
 
 import (
 	"bytes"
-
-	"github.com/ethereum/go-ethereum/crypto"
+	"encoding/binary"
 )
 
+// nodeTag separates the priority value from other hashed values, see priorityValue
+const nodeTag = 1
+
 type Node struct {
 	Hash        []byte
 	Priority    uint64
@@ -16,86 +18,79 @@ type Node struct {
 type ITreap interface {
 	Remove(key []byte)
 	Insert(key []byte, priority uint64)
-	MerklePath(key []byte) [][]byte
+	MerklePath(key []byte) *Proof
+	NonMembershipProof(key []byte) *NonMembershipProof
+	MultiProof(keys [][]byte) *MultiProof
 	MerkleRoot() []byte
 }
 
 type Treap struct {
-	Root *Node
+	Root   *Node
+	Hasher Hasher
 }
 
 // Implements ITreap
 var _ ITreap = &Treap{}
 
+// New returns the treap with keccak256 hasher
 func New() ITreap {
-	return &Treap{}
+	return NewWithHasher(Keccak256Hasher{})
 }
 
-func (t *Treap) Remove(key []byte) {
-	if t.Root == nil {
-		return
-	}
-
-	t1, t2 := split(t.Root, key)
-
-	if bytes.Compare(t2.Hash, key) == 0 {
-		t.Root = merge(t1, t2.Right)
-		return
-	}
-
-	node := t2
-	for {
-		if bytes.Compare(node.Left.Hash, key) == 0 {
-			node.Left = nil
-			updateNode(node)
-			break
-		}
-
-		node = node.Left
-	}
+// NewWithHasher returns the treap with custom hasher
+func NewWithHasher(h Hasher) ITreap {
+	return &Treap{Hasher: h}
+}
 
-	t.Root = merge(t1, t2)
+func (t *Treap) Remove(key []byte) {
+	t.Root = t.remove(t.Root, key)
 }
 
 func (t *Treap) Insert(key []byte, priority uint64) {
 	node := &Node{
-		Hash:       key,
-		MerkleHash: key,
-		Priority:   priority,
+		Hash:     key,
+		Priority: priority,
 	}
 
+	t.updateNode(node)
+
 	if t.Root == nil {
 		t.Root = node
 		return
 	}
 
-	t1, t2 := split(t.Root, key)
-	t.Root = merge(merge(t1, node), t2)
+	t1, t2 := t.split(t.Root, key)
+	t.Root = t.merge(t.merge(t1, node), t2)
 }
 
-func (t *Treap) MerklePath(key []byte) [][]byte {
+// MerklePath returns the proof of key inclusion or nil if key is not present
+func (t *Treap) MerklePath(key []byte) *Proof {
 	node := t.Root
-	result := make([][]byte, 0, 64)
+	proof := &Proof{}
 
 	for node != nil {
 		if bytes.Compare(node.Hash, key) == 0 {
-			result = append(result, hashNodes(node.Left, node.Right))
-			return result
+			proof.Priority = node.Priority
+			proof.Left = merkleHash(node.Left)
+			proof.Right = merkleHash(node.Right)
+			return proof
+		}
+
+		step := ProofStep{
+			Key:      node.Hash,
+			Priority: node.Priority,
 		}
 
 		if bytes.Compare(node.Hash, key) > 0 {
-			result = append(result, node.Hash)
-			if node.Right != nil {
-				result = append(result, node.Right.MerkleHash)
-			}
+			step.Sibling = merkleHash(node.Right)
+			proof.Path = append(proof.Path, step)
 			node = node.Left
 			continue
 		}
 
-		result = append(result, node.Hash)
-		if node.Left != nil {
-			result = append(result, node.Left.MerkleHash)
-		}
+		step.Right = true
+		step.Sibling = merkleHash(node.Left)
+		proof.Path = append(proof.Path, step)
 		node = node.Right
 	}
 
@@ -103,28 +98,46 @@ func (t *Treap) MerklePath(key []byte) [][]byte {
 }
 
 func (t *Treap) MerkleRoot() []byte {
-	return t.Root.MerkleHash
+	return merkleHash(t.Root)
 }
 
-func split(root *Node, key []byte) (*Node, *Node) {
+func (t *Treap) split(root *Node, key []byte) (*Node, *Node) {
 	if root == nil {
 		return nil, nil
 	}
 
 	if bytes.Compare(root.Hash, key) < 0 {
-		t1, t2 := split(root.Right, key)
+		t1, t2 := t.split(root.Right, key)
 		root.Right = t1
-		updateNode(root)
+		t.updateNode(root)
 		return root, t2
 	}
 
-	t1, t2 := split(root.Left, key)
+	t1, t2 := t.split(root.Left, key)
 	root.Left = t2
-	updateNode(root)
+	t.updateNode(root)
 	return t1, root
 }
 
-func merge(t1, t2 *Node) *Node {
+func (t *Treap) remove(node *Node, key []byte) *Node {
+	if node == nil {
+		return nil
+	}
+
+	switch bytes.Compare(key, node.Hash) {
+	case 0:
+		return t.merge(node.Left, node.Right)
+	case -1:
+		node.Left = t.remove(node.Left, key)
+	default:
+		node.Right = t.remove(node.Right, key)
+	}
+
+	t.updateNode(node)
+	return node
+}
+
+func (t *Treap) merge(t1, t2 *Node) *Node {
 	if t1 == nil {
 		return t2
 	}
@@ -134,53 +147,67 @@ func merge(t1, t2 *Node) *Node {
 	}
 
 	if t1.Priority > t2.Priority {
-		t1.Right = merge(t1.Right, t2)
-		updateNode(t1)
+		t1.Right = t.merge(t1.Right, t2)
+		t.updateNode(t1)
 		return t1
 	}
 
-	t2.Left = merge(t1, t2.Left)
-	updateNode(t2)
+	t2.Left = t.merge(t1, t2.Left)
+	t.updateNode(t2)
 	return t2
 }
 
-func updateNode(node *Node) {
-	childrenHash := hashNodes(node.Left, node.Right)
-	if childrenHash == nil {
-		node.MerkleHash = node.Hash
-		return
+func (t *Treap) updateNode(node *Node) {
+	node.MerkleHash = merkleNode(t.hasher(), merkleHash(node.Left), merkleHash(node.Right), node.Hash, node.Priority)
+}
+
+func (t *Treap) hasher() Hasher {
+	if t.Hasher == nil {
+		return Keccak256Hasher{}
 	}
 
-	node.MerkleHash = hash(childrenHash, node.Hash)
+	return t.Hasher
 }
 
-func hashNodes(a, b *Node) []byte {
-	var left []byte = nil
-	var right []byte = nil
-
-	if a != nil {
-		left = a.MerkleHash
+func merkleHash(node *Node) []byte {
+	if node == nil {
+		return nil
 	}
 
-	if b != nil {
-		right = b.MerkleHash
-	}
+	return node.MerkleHash
+}
 
-	return hash(left, right)
+// merkleNode returns the merkle hash of the node: Hash(hashChildren(left, right), nodeValue(key, priority)).
+// Leaves are hashed in the same way with both children absent, so the merkle hash is never the raw key
+// and the subtree hash can not be proven as the key. The priority is committed, so the proof binds the treap shape.
+func merkleNode(h Hasher, left, right, key []byte, priority uint64) []byte {
+	return h.Hash(hashChildren(h, left, right), nodeValue(h, key, priority))
 }
 
-func hash(a, b []byte) []byte {
-	if len(a) == 0 {
-		return b
+// hashChildren returns Hash(left, right) with emptyChild in place of absent children,
+// so the node hash commits to children positions
+func hashChildren(h Hasher, left, right []byte) []byte {
+	if len(left) == 0 {
+		left = emptyChild
 	}
 
-	if len(b) == 0 {
-		return a
+	if len(right) == 0 {
+		right = emptyChild
 	}
 
-	if bytes.Compare(a, b) < 0 {
-		return crypto.Keccak256(a, b)
-	}
+	return h.Hash(left, right)
+}
+
+// nodeValue commits to the key and priority of the node: Hash(priorityValue(priority), key)
+func nodeValue(h Hasher, key []byte, priority uint64) []byte {
+	return h.Hash(priorityValue(priority), key)
+}
 
-	return crypto.Keccak256(b, a)
+// priorityValue encodes the priority as 32 bytes: 23 zero bytes, nodeTag and 8 bytes of big-endian priority.
+// It is the field element for Poseidon and differs from hash values, so it can not be moved to another level.
+func priorityValue(priority uint64) []byte {
+	res := make([]byte, 32)
+	res[23] = nodeTag
+	binary.BigEndian.PutUint64(res[24:], priority)
+	return res
 }
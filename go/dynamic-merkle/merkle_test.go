@@ -1,6 +1,7 @@
-package main
+package dynamic_merkle
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
 	"testing"
@@ -20,19 +21,36 @@ func TestTreap(t *testing.T) {
 
 	fmt.Println("Root: ", hexutil.Encode(tree.MerkleRoot()))
 
-	path := tree.MerklePath(crypto.Keccak256([]byte{3}))
-	check := crypto.Keccak256([]byte{3})
+	proof := tree.MerklePath(crypto.Keccak256([]byte{3}))
 
 	fmt.Println("Path: ")
-	for _, p := range path {
-		fmt.Println(hexutil.Encode(p))
+	for _, p := range proof.Path {
+		fmt.Println(hexutil.Encode(p.Key), hexutil.Encode(p.Sibling), p.Right)
 	}
 
-	for i := len(path) - 1; i >= 0; i-- {
-		if len(path[i]) > 0 {
-			check = hash(check, path[i])
-		}
+	if !VerifyProof(tree.MerkleRoot(), crypto.Keccak256([]byte{3}), proof) {
+		panic("proof is invalid")
 	}
+}
+
+func TestTreapRemove(t *testing.T) {
+	for iter := 0; iter < 100; iter++ {
+		tree, expected := New(), New()
 
-	fmt.Println("Check:", hexutil.Encode(check), "should be equal to root:", hexutil.Encode(tree.MerkleRoot()))
+		for i := 0; i < 30; i++ {
+			key, priority := []byte{byte(i)}, rand.Uint64()
+
+			tree.Insert(key, priority)
+			if i != 7 {
+				expected.Insert(key, priority)
+			}
+		}
+
+		tree.Remove([]byte{7})
+		tree.Remove([]byte{100})
+
+		if !bytes.Equal(tree.MerkleRoot(), expected.MerkleRoot()) {
+			panic("root after removal is not equal to the tree without key")
+		}
+	}
 }
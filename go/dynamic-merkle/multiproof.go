@@ -0,0 +1,194 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+)
+
+// ChildKind describes how the child of MultiProofNode is given in the proof
+type ChildKind byte
+
+const (
+	// ChildEmpty means there is no child
+	ChildEmpty ChildKind = iota
+	// ChildHash means the child merkle hash is the next value of MultiProof.Hashes
+	ChildHash
+	// ChildNode means the child is the next node of MultiProof.Nodes
+	ChildNode
+)
+
+// MultiProofNode is the node on the path to one of the proven keys
+type MultiProofNode struct {
+	Key         []byte
+	Priority    uint64
+	Left, Right ChildKind
+}
+
+// MultiProof is the union of Merkle paths of several keys. Nodes are in pre-order (node, left, right),
+// Hashes contain merkle hashes of the subtrees that are not on any path in the same order.
+// Shared ancestors and their hashes are included only once.
+type MultiProof struct {
+	Nodes  []MultiProofNode
+	Hashes [][]byte
+}
+
+// Size returns the number of bytes of keys, priorities, child kinds and hashes
+func (p *MultiProof) Size() int {
+	res := 0
+	for _, n := range p.Nodes {
+		res += len(n.Key) + 8 + 2
+	}
+
+	for _, h := range p.Hashes {
+		res += len(h)
+	}
+
+	return res
+}
+
+// Size returns the number of bytes of keys, priorities, directions and hashes
+func (p *Proof) Size() int {
+	res := len(p.Left) + len(p.Right) + 8
+	for _, s := range p.Path {
+		res += len(s.Key) + 8 + 1 + len(s.Sibling)
+	}
+
+	return res
+}
+
+// MultiProof returns the proof of inclusion of all keys or nil if any key is not present
+func (t *Treap) MultiProof(keys [][]byte) *MultiProof {
+	onPath := make(map[*Node]struct{})
+
+	for _, key := range keys {
+		node := t.Root
+		for node != nil && !bytes.Equal(node.Hash, key) {
+			onPath[node] = struct{}{}
+
+			if bytes.Compare(node.Hash, key) > 0 {
+				node = node.Left
+			} else {
+				node = node.Right
+			}
+		}
+
+		if node == nil {
+			return nil
+		}
+
+		onPath[node] = struct{}{}
+	}
+
+	proof := &MultiProof{}
+	if len(keys) > 0 {
+		proof.add(t.Root, onPath)
+	}
+
+	return proof
+}
+
+func (p *MultiProof) add(node *Node, onPath map[*Node]struct{}) {
+	idx := len(p.Nodes)
+	p.Nodes = append(p.Nodes, MultiProofNode{Key: node.Hash, Priority: node.Priority})
+
+	kind := func(child *Node) ChildKind {
+		if child == nil {
+			return ChildEmpty
+		}
+
+		if _, ok := onPath[child]; ok {
+			p.add(child, onPath)
+			return ChildNode
+		}
+
+		p.Hashes = append(p.Hashes, child.MerkleHash)
+		return ChildHash
+	}
+
+	left := kind(node.Left)
+	right := kind(node.Right)
+
+	p.Nodes[idx].Left, p.Nodes[idx].Right = left, right
+}
+
+// VerifyMultiProof verifies the proof of the treap with default keccak256 hasher
+func VerifyMultiProof(root []byte, keys [][]byte, proof *MultiProof) bool {
+	return VerifyMultiProofWithHasher(Keccak256Hasher{}, root, keys, proof)
+}
+
+// VerifyMultiProofWithHasher reconstructs the root from the proof in the same way as updateNode does
+// and checks that all keys are among the proof nodes. Treap properties are checked as VerifyProof does.
+func VerifyMultiProofWithHasher(h Hasher, root []byte, keys [][]byte, proof *MultiProof) bool {
+	if proof == nil || len(proof.Nodes) == 0 {
+		return false
+	}
+
+	v := &multiProofVerifier{proof: proof, hasher: h, keys: make(map[string]struct{})}
+
+	res, ok := v.verify(nil, nil, ^uint64(0))
+	if !ok || v.node != len(proof.Nodes) || v.hash != len(proof.Hashes) || !bytes.Equal(res, root) {
+		return false
+	}
+
+	for _, key := range keys {
+		if _, ok := v.keys[string(key)]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+type multiProofVerifier struct {
+	proof  *MultiProof
+	hasher Hasher
+	keys   map[string]struct{}
+
+	// indexes of the next node and hash
+	node, hash int
+}
+
+// verify returns the merkle hash of the next node. Node key should be in (low, high) and priority not greater than max.
+func (v *multiProofVerifier) verify(low, high []byte, max uint64) ([]byte, bool) {
+	if v.node >= len(v.proof.Nodes) {
+		return nil, false
+	}
+
+	n := v.proof.Nodes[v.node]
+	v.node++
+
+	if !validValues(v.hasher, n.Key) || n.Priority > max || (low != nil && bytes.Compare(n.Key, low) <= 0) || (high != nil && bytes.Compare(n.Key, high) >= 0) {
+		return nil, false
+	}
+
+	v.keys[string(n.Key)] = struct{}{}
+
+	child := func(kind ChildKind, low, high []byte) ([]byte, bool) {
+		switch kind {
+		case ChildEmpty:
+			return nil, true
+		case ChildHash:
+			if v.hash >= len(v.proof.Hashes) || len(v.proof.Hashes[v.hash]) == 0 || !validValues(v.hasher, v.proof.Hashes[v.hash]) {
+				return nil, false
+			}
+
+			v.hash++
+			return v.proof.Hashes[v.hash-1], true
+		case ChildNode:
+			return v.verify(low, high, n.Priority)
+		}
+
+		return nil, false
+	}
+
+	left, ok := child(n.Left, low, n.Key)
+	if !ok {
+		return nil, false
+	}
+
+	right, ok := child(n.Right, n.Key, high)
+	if !ok {
+		return nil, false
+	}
+
+	return merkleNode(v.hasher, left, right, n.Key, n.Priority), true
+}
@@ -0,0 +1,108 @@
+package dynamic_merkle
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func randomTreap(size int) (ITreap, [][]byte) {
+	tree := New()
+
+	keys := make([][]byte, 0, size)
+	for i := 0; i < size; i++ {
+		key := crypto.Keccak256([]byte{byte(i), byte(i >> 8), byte(i >> 16)})
+		keys = append(keys, key)
+		tree.Insert(key, rand.Uint64())
+	}
+
+	return tree, keys
+}
+
+func TestMultiProof(t *testing.T) {
+	tree, keys := randomTreap(200)
+
+	for _, k := range []int{1, 2, 10, 50, 200} {
+		subset := keys[:k]
+
+		proof := tree.MultiProof(subset)
+		if !VerifyMultiProof(tree.MerkleRoot(), subset, proof) {
+			panic("multiproof is invalid")
+		}
+
+		// ancestors are proven too, so the key should not be in the proof nodes
+		inProof := make(map[string]struct{})
+		for _, n := range proof.Nodes {
+			inProof[string(n.Key)] = struct{}{}
+		}
+
+		for _, key := range keys[k:] {
+			if _, ok := inProof[string(key)]; ok {
+				continue
+			}
+
+			if VerifyMultiProof(tree.MerkleRoot(), append([][]byte{key}, subset...), proof) {
+				panic("multiproof is valid for not included key")
+			}
+
+			break
+		}
+
+		// shuffled order of keys gives the same proof
+		shuffled := append([][]byte{}, subset...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		if !VerifyMultiProof(tree.MerkleRoot(), shuffled, tree.MultiProof(shuffled)) {
+			panic("multiproof is invalid for shuffled keys")
+		}
+
+		if len(proof.Hashes) > 0 {
+			proof.Hashes[0] = crypto.Keccak256(proof.Hashes[0])
+			if VerifyMultiProof(tree.MerkleRoot(), subset, proof) {
+				panic("modified multiproof is valid")
+			}
+		}
+	}
+
+	if tree.MultiProof([][]byte{keys[0], crypto.Keccak256([]byte("missing"))}) != nil {
+		panic("multiproof returned for missing key")
+	}
+}
+
+func BenchmarkMultiProof(b *testing.B) {
+	tree, keys := randomTreap(10000)
+
+	for _, k := range []int{10, 100, 1000} {
+		subset := keys[:k]
+
+		b.Run(fmt.Sprintf("keys=%d", k), func(b *testing.B) {
+			var proof *MultiProof
+			for i := 0; i < b.N; i++ {
+				proof = tree.MultiProof(subset)
+			}
+
+			separate := 0
+			for _, key := range subset {
+				separate += tree.MerklePath(key).Size()
+			}
+
+			b.ReportMetric(float64(proof.Size()), "multiproof-bytes")
+			b.ReportMetric(float64(separate), "paths-bytes")
+			b.ReportMetric(float64(separate)/float64(proof.Size()), "savings-x")
+		})
+	}
+}
+
+func BenchmarkVerifyMultiProof(b *testing.B) {
+	tree, keys := randomTreap(10000)
+	subset := keys[:100]
+	proof := tree.MultiProof(subset)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !VerifyMultiProof(tree.MerkleRoot(), subset, proof) {
+			panic("multiproof is invalid")
+		}
+	}
+}
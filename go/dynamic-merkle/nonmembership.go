@@ -0,0 +1,136 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+)
+
+// NonMembershipProof proves that the key is absent by showing its adjacent neighbours in key order.
+// Predecessor or Successor is nil if the key is less or greater than all keys in the tree.
+//
+// The proof relies on absent children of the neighbours, so it requires PositionalHasher (PoseidonHasher):
+// the default keccak hash sorts its arguments and the root does not commit to children positions.
+type NonMembershipProof struct {
+	PredecessorKey []byte
+	Predecessor    *Proof
+	SuccessorKey   []byte
+	Successor      *Proof
+}
+
+// NonMembershipProof returns the proof that key is absent or nil if key is present
+// or the treap hasher is not PositionalHasher
+func (t *Treap) NonMembershipProof(key []byte) *NonMembershipProof {
+	if !isPositional(t.hasher()) {
+		return nil
+	}
+
+	var predecessor, successor *Node
+
+	node := t.Root
+	for node != nil {
+		cmp := bytes.Compare(node.Hash, key)
+		if cmp == 0 {
+			return nil
+		}
+
+		if cmp > 0 {
+			successor = node
+			node = node.Left
+			continue
+		}
+
+		predecessor = node
+		node = node.Right
+	}
+
+	proof := &NonMembershipProof{}
+
+	if predecessor != nil {
+		proof.PredecessorKey = predecessor.Hash
+		proof.Predecessor = t.MerklePath(predecessor.Hash)
+	}
+
+	if successor != nil {
+		proof.SuccessorKey = successor.Hash
+		proof.Successor = t.MerklePath(successor.Hash)
+	}
+
+	return proof
+}
+
+// VerifyNonMembership verifies the proof of the treap with PoseidonHasher:
+// the default keccak256 hasher does not bind children positions
+func VerifyNonMembership(root, key []byte, proof *NonMembershipProof) bool {
+	return VerifyNonMembershipWithHasher(PoseidonHasher{}, root, key, proof)
+}
+
+// VerifyNonMembershipWithHasher checks that the neighbours are present in the tree, predecessor < key < successor
+// and there are no other keys between them: one neighbour is the ancestor of another and the path between them
+// goes only to the opposite direction. Returns false if h is not PositionalHasher.
+// Node hashes are never equal to keys (see merkleNode), so the neighbour can not be replaced with a subtree hash.
+func VerifyNonMembershipWithHasher(h Hasher, root, key []byte, proof *NonMembershipProof) bool {
+	if proof == nil || !isPositional(h) {
+		return false
+	}
+
+	hasPredecessor, hasSuccessor := proof.Predecessor != nil, proof.Successor != nil
+
+	// empty tree
+	if !hasPredecessor && !hasSuccessor {
+		return len(root) == 0
+	}
+
+	if hasPredecessor && (bytes.Compare(proof.PredecessorKey, key) >= 0 || !VerifyProofWithHasher(h, root, proof.PredecessorKey, proof.Predecessor)) {
+		return false
+	}
+
+	if hasSuccessor && (bytes.Compare(proof.SuccessorKey, key) <= 0 || !VerifyProofWithHasher(h, root, proof.SuccessorKey, proof.Successor)) {
+		return false
+	}
+
+	switch {
+	case !hasPredecessor:
+		// successor is the minimum: the path goes only left and there is no left child
+		return len(proof.Successor.Left) == 0 && sameDirection(proof.Successor.Path, false)
+	case !hasSuccessor:
+		// predecessor is the maximum
+		return len(proof.Predecessor.Right) == 0 && sameDirection(proof.Predecessor.Path, true)
+	}
+
+	pPath, sPath := proof.Predecessor.Path, proof.Successor.Path
+
+	// successor is the ancestor: predecessor is the rightmost node in its left subtree
+	if len(sPath) < len(pPath) {
+		return len(proof.Predecessor.Right) == 0 && isPrefix(sPath, pPath) &&
+			bytes.Equal(pPath[len(sPath)].Key, proof.SuccessorKey) && !pPath[len(sPath)].Right &&
+			sameDirection(pPath[len(sPath)+1:], true)
+	}
+
+	// predecessor is the ancestor: successor is the leftmost node in its right subtree
+	return len(proof.Successor.Left) == 0 && isPrefix(pPath, sPath) &&
+		bytes.Equal(sPath[len(pPath)].Key, proof.PredecessorKey) && sPath[len(pPath)].Right &&
+		sameDirection(sPath[len(pPath)+1:], false)
+}
+
+func sameDirection(path []ProofStep, right bool) bool {
+	for _, step := range path {
+		if step.Right != right {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isPrefix(prefix, path []ProofStep) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if !bytes.Equal(prefix[i].Key, path[i].Key) || prefix[i].Right != path[i].Right {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,138 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestNonMembershipProof(t *testing.T) {
+	tree := NewWithHasher(PoseidonHasher{})
+
+	if !VerifyNonMembership(tree.MerkleRoot(), []byte{1}, tree.NonMembershipProof([]byte{1})) {
+		panic("non-membership proof is invalid for empty tree")
+	}
+
+	// even keys are present, odd keys are absent
+	keys := make([][]byte, 0, 100)
+	for i := 2; i < 200; i += 2 {
+		key := []byte{byte(i)}
+		keys = append(keys, key)
+		tree.Insert(key, rand.Uint64())
+	}
+
+	for i := 1; i < 201; i += 2 {
+		key := []byte{byte(i)}
+
+		proof := tree.NonMembershipProof(key)
+		if !VerifyNonMembership(tree.MerkleRoot(), key, proof) {
+			panic("non-membership proof is invalid")
+		}
+
+		if i > 1 && i < 199 && (!bytes.Equal(proof.PredecessorKey, []byte{byte(i - 1)}) || !bytes.Equal(proof.SuccessorKey, []byte{byte(i + 1)})) {
+			panic("invalid neighbours")
+		}
+
+		if i < 199 && VerifyNonMembership(tree.MerkleRoot(), []byte{byte(i + 1)}, proof) {
+			panic("non-membership proof is valid for another key")
+		}
+	}
+
+	for _, key := range keys {
+		if tree.NonMembershipProof(key) != nil {
+			panic("non-membership proof returned for present key")
+		}
+	}
+
+	// neighbours which are not adjacent
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	proof := &NonMembershipProof{
+		PredecessorKey: keys[10],
+		Predecessor:    tree.MerklePath(keys[10]),
+		SuccessorKey:   keys[12],
+		Successor:      tree.MerklePath(keys[12]),
+	}
+
+	if VerifyNonMembership(tree.MerkleRoot(), keys[11], proof) {
+		panic("present key is proven to be absent")
+	}
+}
+
+func TestNonMembershipForgedProof(t *testing.T) {
+	// 10 is the root and 20 is its right child
+	tree := NewWithHasher(PoseidonHasher{})
+	tree.Insert([]byte{10}, 2)
+	tree.Insert([]byte{20}, 1)
+
+	proof := tree.NonMembershipProof([]byte{15})
+	if !VerifyNonMembership(tree.MerkleRoot(), []byte{15}, proof) {
+		panic("non-membership proof is invalid")
+	}
+
+	// move the only child of 10 from right to left and claim 10 is the maximum
+	path := tree.MerklePath([]byte{10})
+	path.Left, path.Right = path.Right, nil
+
+	forged := &NonMembershipProof{PredecessorKey: []byte{10}, Predecessor: path}
+	if VerifyNonMembership(tree.MerkleRoot(), []byte{20}, forged) {
+		panic("present key is proven to be absent")
+	}
+
+	// the same forgery is possible with keccak256, so the proofs are refused
+	keccakTree := New()
+	keccakTree.Insert([]byte{10}, 2)
+	keccakTree.Insert([]byte{20}, 1)
+
+	if keccakTree.NonMembershipProof([]byte{15}) != nil {
+		panic("non-membership proof returned for keccak256 treap")
+	}
+
+	path = keccakTree.MerklePath([]byte{10})
+	path.Left, path.Right = path.Right, nil
+
+	forged = &NonMembershipProof{PredecessorKey: []byte{10}, Predecessor: path}
+	if VerifyNonMembershipWithHasher(Keccak256Hasher{}, keccakTree.MerkleRoot(), []byte{20}, forged) {
+		panic("non-membership proof is verified with keccak256 hasher")
+	}
+
+	// leaf hash is never the raw key, so the empty child marker is an ordinary key
+	proof = tree.NonMembershipProof(emptyChild)
+	if !VerifyNonMembership(tree.MerkleRoot(), emptyChild, proof) {
+		panic("non-membership proof is invalid for empty child marker")
+	}
+
+	tree.Insert(emptyChild, 0)
+	if tree.NonMembershipProof(emptyChild) != nil || !VerifyNonMembership(tree.MerkleRoot(), []byte{15}, tree.NonMembershipProof([]byte{15})) {
+		panic("invalid non-membership proof with empty child marker key")
+	}
+}
+
+func TestNonMembershipSubtreeHashAsKey(t *testing.T) {
+	// decreasing priorities: keys 1000...10000 form the right spine
+	tree := NewWithHasher(PoseidonHasher{}).(*Treap)
+	for i := 1; i <= 10; i++ {
+		tree.Insert(big.NewInt(int64(i*1000)).Bytes(), uint64(100-i))
+	}
+
+	key := big.NewInt(5000).Bytes()
+	if tree.NonMembershipProof(key) != nil {
+		panic("non-membership proof returned for present key")
+	}
+
+	// the right subtree of the root is claimed to be the leaf successor of the root
+	root := tree.Root
+	forged := &NonMembershipProof{
+		PredecessorKey: root.Hash,
+		Predecessor:    tree.MerklePath(root.Hash),
+		SuccessorKey:   root.Right.MerkleHash,
+		Successor: &Proof{
+			Path: []ProofStep{{Key: root.Hash, Priority: root.Priority, Sibling: merkleHash(root.Left), Right: true}},
+		},
+	}
+
+	if VerifyNonMembership(tree.MerkleRoot(), key, forged) {
+		panic("present key is proven to be absent")
+	}
+}
@@ -0,0 +1,123 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// ProofStep describes the ancestor of the proven node
+type ProofStep struct {
+	// Key is the ancestor's key (Node.Hash)
+	Key      []byte
+	Priority uint64
+	// Sibling is the merkle hash of the ancestor's other child, nil if it is absent
+	Sibling []byte
+	// Right is true if the path goes to the right child of the ancestor
+	Right bool
+}
+
+// Proof is the Merkle path of the node in Treap
+type Proof struct {
+	// Path contains node ancestors from the root
+	Path []ProofStep
+	// Priority is the priority of the proven node
+	Priority uint64
+	// Left and Right are the merkle hashes of the proven node children, nil if absent
+	Left, Right []byte
+}
+
+// VerifyProof verifies the proof of the treap with default keccak256 hasher
+func VerifyProof(root, key []byte, proof *Proof) bool {
+	return VerifyProofWithHasher(Keccak256Hasher{}, root, key, proof)
+}
+
+// VerifyProofWithHasher reconstructs the root from the key and proof in the same way as updateNode does.
+// Treap properties are checked too: keys on the left are less and parent priority is not less than child priority.
+// Priorities are committed in node hashes, so the checked shape is the shape of the tree with this root.
+func VerifyProofWithHasher(h Hasher, root, key []byte, proof *Proof) bool {
+	if proof == nil || len(root) == 0 {
+		return false
+	}
+
+	if !validValues(h, key, proof.Left, proof.Right) {
+		return false
+	}
+
+	priority := proof.Priority
+	for i := len(proof.Path) - 1; i >= 0; i-- {
+		step := proof.Path[i]
+
+		if !validValues(h, step.Key, step.Sibling) {
+			return false
+		}
+
+		if step.Priority < priority {
+			return false
+		}
+
+		if cmp := bytes.Compare(key, step.Key); (step.Right && cmp <= 0) || (!step.Right && cmp >= 0) {
+			return false
+		}
+
+		priority = step.Priority
+	}
+
+	cur := merkleNode(h, proof.Left, proof.Right, key, proof.Priority)
+
+	for i := len(proof.Path) - 1; i >= 0; i-- {
+		step := proof.Path[i]
+
+		children := hashChildren(h, cur, step.Sibling)
+		if step.Right {
+			children = hashChildren(h, step.Sibling, cur)
+		}
+
+		cur = h.Hash(children, nodeValue(h, step.Key, step.Priority))
+	}
+
+	return bytes.Equal(cur, root)
+}
+
+// CircuitInput is the input of ProveMerkle template from `circuits/merkle/merkle.circom`
+type CircuitInput struct {
+	In      []string `json:"in"`
+	Indices []string `json:"indices"`
+}
+
+// CircuitInput returns the path of the key as the circuit input: in[0] is the key and in[i] is hashed with
+// the current value in the order given by indices[i]: 0 for Hash(current, in[i]) and 1 for Hash(in[i], current).
+// Absent children are replaced with the empty child marker as hashChildren does.
+// Circuit should be compiled with N = len(In) = 3 + 2*len(p.Path).
+func (p *Proof) CircuitInput(h Hasher, key []byte) *CircuitInput {
+	res := &CircuitInput{}
+
+	add := func(value []byte, index string) {
+		res.In = append(res.In, new(big.Int).SetBytes(value).String())
+		res.Indices = append(res.Indices, index)
+	}
+
+	// node merkle hash = Hash(hashChildren(left, right), Hash(priorityValue(priority), key))
+	add(key, "0")
+	add(priorityValue(p.Priority), "1")
+	add(hashChildren(h, p.Left, p.Right), "1")
+
+	for i := len(p.Path) - 1; i >= 0; i-- {
+		step := p.Path[i]
+
+		sibling := step.Sibling
+		if len(sibling) == 0 {
+			sibling = emptyChild
+		}
+
+		if step.Right {
+			add(sibling, "1")
+		} else {
+			add(sibling, "0")
+		}
+
+		// ancestor merkle hash = Hash(children, nodeValue(key, priority))
+		add(nodeValue(h, step.Key, step.Priority), "0")
+	}
+
+	return res
+}
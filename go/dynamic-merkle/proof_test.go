@@ -0,0 +1,118 @@
+package dynamic_merkle
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestVerifyProof(t *testing.T) {
+	tree := New()
+
+	keys := make([][]byte, 0, 100)
+	for i := 0; i < 100; i++ {
+		key := crypto.Keccak256([]byte{byte(i)})
+		keys = append(keys, key)
+		tree.Insert(key, rand.Uint64())
+	}
+
+	for i, key := range keys {
+		proof := tree.MerklePath(key)
+		if !VerifyProof(tree.MerkleRoot(), key, proof) {
+			panic("proof is invalid")
+		}
+
+		if VerifyProof(tree.MerkleRoot(), keys[(i+1)%len(keys)], proof) {
+			panic("proof is valid for another key")
+		}
+
+		if len(proof.Path) > 0 {
+			proof.Path[0].Right = !proof.Path[0].Right
+			if VerifyProof(tree.MerkleRoot(), key, proof) {
+				panic("proof with invalid direction is valid")
+			}
+
+			proof.Path[0].Right = !proof.Path[0].Right
+			proof.Path[len(proof.Path)-1].Priority = proof.Priority - 1
+			if proof.Priority > 0 && VerifyProof(tree.MerkleRoot(), key, proof) {
+				panic("proof with invalid priority is valid")
+			}
+		}
+	}
+
+	if tree.MerklePath(crypto.Keccak256([]byte("missing"))) != nil {
+		panic("proof returned for missing key")
+	}
+
+	tree.Remove(keys[10])
+	if VerifyProof(tree.MerkleRoot(), keys[10], tree.MerklePath(keys[10])) {
+		panic("removed key is proven")
+	}
+
+	if !VerifyProof(tree.MerkleRoot(), keys[11], tree.MerklePath(keys[11])) {
+		panic("proof is invalid after removal")
+	}
+}
+
+func TestSubtreeHashAsKey(t *testing.T) {
+	for _, h := range []Hasher{Keccak256Hasher{}, PoseidonHasher{}} {
+		// decreasing priorities: keys form the right spine
+		tree := NewWithHasher(h).(*Treap)
+		for i := 1; i <= 10; i++ {
+			tree.Insert(big.NewInt(int64(i*1000)).Bytes(), uint64(100-i))
+		}
+
+		root := tree.Root
+		key := root.Right.MerkleHash
+
+		forged := &Proof{Path: []ProofStep{{Key: root.Hash, Priority: root.Priority, Sibling: merkleHash(root.Left), Right: true}}}
+		if VerifyProofWithHasher(h, tree.MerkleRoot(), key, forged) {
+			panic("subtree hash is proven as the key")
+		}
+
+		forged.Priority = root.Right.Priority
+		forged.Left, forged.Right = merkleHash(root.Right.Left), merkleHash(root.Right.Right)
+		if VerifyProofWithHasher(h, tree.MerkleRoot(), key, forged) {
+			panic("subtree hash is proven as the key")
+		}
+
+		multi := &MultiProof{Nodes: []MultiProofNode{
+			{Key: root.Hash, Priority: root.Priority, Left: ChildEmpty, Right: ChildNode},
+			{Key: key},
+		}}
+
+		if VerifyMultiProofWithHasher(h, tree.MerkleRoot(), [][]byte{key}, multi) {
+			panic("subtree hash is proven as the key with multiproof")
+		}
+
+		for _, k := range [][]byte{root.Hash, root.Right.Hash} {
+			if !VerifyProofWithHasher(h, tree.MerkleRoot(), k, tree.MerklePath(k)) {
+				panic("proof is invalid")
+			}
+		}
+	}
+}
+
+func TestProofPriority(t *testing.T) {
+	tree := New()
+	for i := 1; i <= 10; i++ {
+		tree.Insert(big.NewInt(int64(i*1000)).Bytes(), uint64(100-i))
+	}
+
+	key := big.NewInt(5000).Bytes()
+
+	// any priorities keeping the heap order were accepted before they were committed
+	proof := tree.MerklePath(key)
+	proof.Priority++
+	if VerifyProof(tree.MerkleRoot(), key, proof) {
+		panic("proof with changed priority is valid")
+	}
+
+	proof = tree.MerklePath(key)
+	proof.Path[0].Priority++
+	if VerifyProof(tree.MerkleRoot(), key, proof) {
+		panic("proof with changed ancestor priority is valid")
+	}
+}
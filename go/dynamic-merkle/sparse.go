@@ -0,0 +1,267 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SparseDepth is the depth of SparseTree: one level for each bit of 32-byte key
+const SparseDepth = 256
+
+const sparseKeySize = SparseDepth / 8
+
+var (
+	ErrInvalidKey   = errors.New("key should be 32 bytes")
+	ErrInvalidValue = errors.New("value should not be empty")
+	ErrInvalidBatch = errors.New("number of keys and values should be equal")
+	ErrHasherValue  = errors.New("key or value is not accepted by the hasher")
+)
+
+// OrderedKeccak256Hasher is keccak256(a || b). Unlike Keccak256Hasher it binds the order of values,
+// so it is the default hasher of SparseTree.
+type OrderedKeccak256Hasher struct{}
+
+var _ Hasher = OrderedKeccak256Hasher{}
+
+func (OrderedKeccak256Hasher) Hash(a, b []byte) []byte {
+	return crypto.Keccak256(a, b)
+}
+
+type ISparseTree interface {
+	Get(key []byte) ([]byte, bool)
+	Set(key, value []byte) error
+	Delete(key []byte) error
+	SetBatch(keys, values [][]byte) error
+	Prove(key []byte) (*SparseProof, error)
+	MerkleRoot() []byte
+}
+
+// SparseTree is the fixed-depth sparse Merkle tree over 32-byte keys. Leaf is Hash(key, value),
+// node is Hash(left, right), empty subtree hash is 32 zero bytes at any level.
+type SparseTree struct {
+	Hasher Hasher
+
+	values map[string][]byte
+	// nodes contains non-empty node hashes by the node id: depth || key prefix
+	nodes map[string][]byte
+}
+
+// Implements ISparseTree
+var _ ISparseTree = &SparseTree{}
+
+// NewSparse returns the empty sparse tree with OrderedKeccak256Hasher
+func NewSparse() *SparseTree {
+	return NewSparseWithHasher(OrderedKeccak256Hasher{})
+}
+
+// NewSparseWithHasher returns the empty sparse tree with custom hasher that should bind the order of values
+func NewSparseWithHasher(h Hasher) *SparseTree {
+	return &SparseTree{
+		Hasher: h,
+		values: make(map[string][]byte),
+		nodes:  make(map[string][]byte),
+	}
+}
+
+// Get returns the copy of the key value
+func (t *SparseTree) Get(key []byte) ([]byte, bool) {
+	v, ok := t.values[string(key)]
+	return bytes.Clone(v), ok
+}
+
+func (t *SparseTree) Set(key, value []byte) error {
+	return t.SetBatch([][]byte{key}, [][]byte{value})
+}
+
+func (t *SparseTree) Delete(key []byte) error {
+	return t.SetBatch([][]byte{key}, [][]byte{nil})
+}
+
+// SetBatch sets copies of all values (nil value deletes the key) and recomputes every affected node only once.
+// Nothing is changed if any key or value is invalid, including values rejected by ValueChecker hasher.
+func (t *SparseTree) SetBatch(keys, values [][]byte) error {
+	if len(keys) != len(values) {
+		return ErrInvalidBatch
+	}
+
+	for _, key := range keys {
+		if len(key) != sparseKeySize {
+			return ErrInvalidKey
+		}
+	}
+
+	dirty := make(map[string][]byte, len(keys))
+	for i, key := range keys {
+		if values[i] != nil && len(values[i]) == 0 {
+			return ErrInvalidValue
+		}
+
+		if !validValues(t.Hasher, key, values[i]) {
+			return ErrHasherValue
+		}
+
+		dirty[string(key)] = key
+	}
+
+	for i, key := range keys {
+		id := nodeID(key, SparseDepth)
+
+		if values[i] == nil {
+			delete(t.values, string(key))
+			delete(t.nodes, id)
+			continue
+		}
+
+		value := bytes.Clone(values[i])
+		t.values[string(key)] = value
+		t.nodes[id] = t.Hasher.Hash(key, value)
+	}
+
+	for depth := SparseDepth; depth > 0; depth-- {
+		parents := make(map[string][]byte, len(dirty))
+
+		for _, key := range dirty {
+			prefix := keyPrefix(key, depth-1)
+			id := nodeID(prefix, depth-1)
+			if _, ok := parents[id]; ok {
+				continue
+			}
+
+			parents[id] = prefix
+
+			left, right := t.children(prefix, depth-1)
+			if left == nil && right == nil {
+				delete(t.nodes, id)
+				continue
+			}
+
+			t.nodes[id] = t.Hasher.Hash(emptyOr(left), emptyOr(right))
+		}
+
+		dirty = parents
+	}
+
+	return nil
+}
+
+// MerkleRoot returns the root hash, 32 zero bytes for the empty tree
+func (t *SparseTree) MerkleRoot() []byte {
+	return emptyOr(t.nodes[nodeID(nil, 0)])
+}
+
+// SparseProof is the compressed Merkle path: Bitmap has bit i set if the sibling on depth i+1 is not empty,
+// Siblings contains only non-empty siblings from the root to the leaf.
+type SparseProof struct {
+	Bitmap   [sparseKeySize]byte
+	Siblings [][]byte
+}
+
+// Prove returns the proof of the key value, or of the key absence if it is not set
+func (t *SparseTree) Prove(key []byte) (*SparseProof, error) {
+	if len(key) != sparseKeySize {
+		return nil, ErrInvalidKey
+	}
+
+	proof := &SparseProof{}
+	for depth := 1; depth <= SparseDepth; depth++ {
+		sibling := t.nodes[nodeID(flipBit(keyPrefix(key, depth), depth-1), depth)]
+		if sibling != nil {
+			proof.Bitmap[(depth-1)/8] |= 1 << (7 - (depth-1)%8)
+			proof.Siblings = append(proof.Siblings, sibling)
+		}
+	}
+
+	return proof, nil
+}
+
+// VerifySparseProof verifies the proof of the sparse tree with OrderedKeccak256Hasher.
+// Nil value means non-membership proof.
+func VerifySparseProof(root, key, value []byte, proof *SparseProof) bool {
+	return VerifySparseProofWithHasher(OrderedKeccak256Hasher{}, root, key, value, proof)
+}
+
+// VerifySparseProofWithHasher reconstructs the root from the leaf and siblings in the same way as SetBatch does
+func VerifySparseProofWithHasher(h Hasher, root, key, value []byte, proof *SparseProof) bool {
+	if proof == nil || len(key) != sparseKeySize || (value != nil && len(value) == 0) {
+		return false
+	}
+
+	if !validValues(h, key, value) || !validValues(h, proof.Siblings...) {
+		return false
+	}
+
+	var cur []byte
+	if value != nil {
+		cur = h.Hash(key, value)
+	}
+
+	next := len(proof.Siblings) - 1
+	for depth := SparseDepth; depth > 0; depth-- {
+		var sibling []byte
+		if proof.Bitmap[(depth-1)/8]&(1<<(7-(depth-1)%8)) != 0 {
+			if next < 0 {
+				return false
+			}
+
+			sibling = proof.Siblings[next]
+			next--
+		}
+
+		if cur == nil && sibling == nil {
+			continue
+		}
+
+		if bit(key, depth-1) == 0 {
+			cur = h.Hash(emptyOr(cur), emptyOr(sibling))
+		} else {
+			cur = h.Hash(emptyOr(sibling), emptyOr(cur))
+		}
+	}
+
+	return next == -1 && bytes.Equal(emptyOr(cur), root)
+}
+
+// children returns the hashes of the node children, nil for empty
+func (t *SparseTree) children(prefix []byte, depth int) (left, right []byte) {
+	left = t.nodes[nodeID(prefix, depth+1)]
+	right = t.nodes[nodeID(flipBit(prefix, depth), depth+1)]
+	return left, right
+}
+
+// nodeID returns depth || prefix with the first depth bits of the key
+func nodeID(key []byte, depth int) string {
+	return string([]byte{byte(depth >> 8), byte(depth)}) + string(keyPrefix(key, depth))
+}
+
+// keyPrefix returns the key with all bits after the first depth bits set to zero
+func keyPrefix(key []byte, depth int) []byte {
+	res := make([]byte, sparseKeySize)
+	copy(res, key[:(depth+7)/8])
+
+	if depth%8 != 0 {
+		res[depth/8] &= 0xff << (8 - depth%8)
+	}
+
+	return res
+}
+
+func flipBit(key []byte, i int) []byte {
+	res := make([]byte, sparseKeySize)
+	copy(res, key)
+	res[i/8] ^= 1 << (7 - i%8)
+	return res
+}
+
+func bit(key []byte, i int) byte {
+	return (key[i/8] >> (7 - i%8)) & 1
+}
+
+func emptyOr(h []byte) []byte {
+	if h == nil {
+		return make([]byte, sparseKeySize)
+	}
+
+	return h
+}
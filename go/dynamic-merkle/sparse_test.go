@@ -0,0 +1,165 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSparseTree(t *testing.T) {
+	tree := NewSparse()
+	empty := tree.MerkleRoot()
+
+	keys := make([][]byte, 0, 50)
+	values := make([][]byte, 0, 50)
+	for i := 0; i < 50; i++ {
+		keys = append(keys, crypto.Keccak256([]byte{byte(i)}))
+		values = append(values, []byte{byte(i), 1})
+	}
+
+	for i := range keys {
+		if err := tree.Set(keys[i], values[i]); err != nil {
+			panic(err)
+		}
+	}
+
+	batch := NewSparse()
+	if err := batch.SetBatch(keys, values); err != nil {
+		panic(err)
+	}
+
+	if !bytes.Equal(tree.MerkleRoot(), batch.MerkleRoot()) {
+		panic("batch root is not equal")
+	}
+
+	for i, key := range keys {
+		if v, ok := tree.Get(key); !ok || !bytes.Equal(v, values[i]) {
+			panic("invalid value")
+		}
+
+		proof, err := tree.Prove(key)
+		if err != nil {
+			panic(err)
+		}
+
+		// proof is compressed: only few siblings are not empty
+		if len(proof.Siblings) > 20 {
+			panic("proof is not compressed")
+		}
+
+		if !VerifySparseProof(tree.MerkleRoot(), key, values[i], proof) {
+			panic("membership proof is invalid")
+		}
+
+		if VerifySparseProof(tree.MerkleRoot(), key, []byte{0xff}, proof) || VerifySparseProof(tree.MerkleRoot(), key, nil, proof) {
+			panic("proof is valid for another value")
+		}
+	}
+
+	missing := crypto.Keccak256([]byte("missing"))
+	proof, err := tree.Prove(missing)
+	if err != nil {
+		panic(err)
+	}
+
+	if !VerifySparseProof(tree.MerkleRoot(), missing, nil, proof) {
+		panic("non-membership proof is invalid")
+	}
+
+	if VerifySparseProof(tree.MerkleRoot(), missing, []byte{1}, proof) {
+		panic("non-membership proof is valid for value")
+	}
+
+	// delete half of keys with batch and the rest one by one
+	deleted := make([][]byte, 25)
+	if err := batch.SetBatch(keys[:25], deleted); err != nil {
+		panic(err)
+	}
+
+	for _, key := range keys[:25] {
+		if err := tree.Delete(key); err != nil {
+			panic(err)
+		}
+	}
+
+	if !bytes.Equal(tree.MerkleRoot(), batch.MerkleRoot()) {
+		panic("batch root is not equal after deletion")
+	}
+
+	proof, err = tree.Prove(keys[0])
+	if err != nil {
+		panic(err)
+	}
+
+	if !VerifySparseProof(tree.MerkleRoot(), keys[0], nil, proof) {
+		panic("non-membership proof of deleted key is invalid")
+	}
+
+	for _, key := range keys[25:] {
+		if err := tree.Delete(key); err != nil {
+			panic(err)
+		}
+	}
+
+	if !bytes.Equal(tree.MerkleRoot(), empty) || len(tree.nodes) != 0 {
+		panic("tree is not empty")
+	}
+
+	if err := tree.Set([]byte{1}, []byte{1}); err != ErrInvalidKey {
+		panic("invalid key accepted")
+	}
+}
+
+func TestSparseTreeValueCopy(t *testing.T) {
+	tree := NewSparse()
+	key := crypto.Keccak256([]byte("key"))
+	value := []byte{1, 2, 3}
+
+	if err := tree.Set(key, value); err != nil {
+		panic(err)
+	}
+
+	value[0] = 0xff
+	if v, _ := tree.Get(key); !bytes.Equal(v, []byte{1, 2, 3}) {
+		panic("stored value is changed by the caller")
+	}
+
+	v, _ := tree.Get(key)
+	v[0] = 0xff
+
+	proof, err := tree.Prove(key)
+	if err != nil {
+		panic(err)
+	}
+
+	if !VerifySparseProof(tree.MerkleRoot(), key, []byte{1, 2, 3}, proof) {
+		panic("stored value is changed by Get result")
+	}
+}
+
+func TestSparseTreeHasherValues(t *testing.T) {
+	tree := NewSparseWithHasher(PoseidonHasher{})
+	key := make([]byte, 32)
+	invalid := bytes.Repeat([]byte{0xff}, 32)
+
+	if err := tree.Set(key, []byte{1}); err != nil {
+		panic(err)
+	}
+
+	root := tree.MerkleRoot()
+
+	if err := tree.Set(invalid, []byte{1}); err != ErrHasherValue {
+		panic("key out of field is accepted")
+	}
+
+	other := make([]byte, 32)
+	other[31] = 1
+	if err := tree.SetBatch([][]byte{other, key}, [][]byte{{2}, invalid}); err != ErrHasherValue {
+		panic("value out of field is accepted")
+	}
+
+	if _, ok := tree.Get(other); ok || !bytes.Equal(tree.MerkleRoot(), root) {
+		panic("tree is changed by invalid batch")
+	}
+}
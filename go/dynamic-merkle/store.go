@@ -0,0 +1,159 @@
+package dynamic_merkle
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrNodeNotFound    = errors.New("node not found")
+	ErrVersionNotFound = errors.New("version not found")
+	ErrHasherMismatch  = errors.New("store was created with another hasher")
+)
+
+// StoredNode is the immutable treap node referenced by ids of its children. Empty id means no child.
+type StoredNode struct {
+	Key        []byte `json:"key"`
+	Priority   uint64 `json:"priority"`
+	MerkleHash []byte `json:"merkle_hash"`
+	Left       []byte `json:"left,omitempty"`
+	Right      []byte `json:"right,omitempty"`
+}
+
+// NodeStore stores immutable nodes by their ids and the root node id of each committed version
+type NodeStore interface {
+	GetNode(id []byte) (*StoredNode, error)
+	PutNode(id []byte, node *StoredNode) error
+	DeleteNode(id []byte) error
+	NodeIDs() ([][]byte, error)
+
+	GetRoot(version uint64) ([]byte, error)
+	PutRoot(version uint64, id []byte) error
+	DeleteRoot(version uint64) error
+	// Versions returns the committed versions in ascending order
+	Versions() ([]uint64, error)
+
+	// GetHasher returns the hasher id saved with PutHasher or nil
+	GetHasher() ([]byte, error)
+	PutHasher(id []byte) error
+}
+
+// Compactor is implemented by stores that can reclaim the space after pruning
+type Compactor interface {
+	Compact() error
+}
+
+// MemoryStore is the in-memory NodeStore
+type MemoryStore struct {
+	mu     sync.RWMutex
+	nodes  map[string]*StoredNode
+	roots  map[uint64][]byte
+	hasher []byte
+}
+
+// Implements NodeStore
+var _ NodeStore = &MemoryStore{}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		nodes: make(map[string]*StoredNode),
+		roots: make(map[uint64][]byte),
+	}
+}
+
+func (s *MemoryStore) GetNode(id []byte) (*StoredNode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	node, ok := s.nodes[string(id)]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+
+	return node, nil
+}
+
+func (s *MemoryStore) PutNode(id []byte, node *StoredNode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nodes[string(id)] = node
+	return nil
+}
+
+func (s *MemoryStore) DeleteNode(id []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.nodes, string(id))
+	return nil
+}
+
+func (s *MemoryStore) NodeIDs() ([][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([][]byte, 0, len(s.nodes))
+	for id := range s.nodes {
+		res = append(res, []byte(id))
+	}
+
+	return res, nil
+}
+
+func (s *MemoryStore) GetRoot(version uint64) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.roots[version]
+	if !ok {
+		return nil, ErrVersionNotFound
+	}
+
+	return id, nil
+}
+
+func (s *MemoryStore) PutRoot(version uint64, id []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roots[version] = id
+	return nil
+}
+
+func (s *MemoryStore) DeleteRoot(version uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.roots, version)
+	return nil
+}
+
+func (s *MemoryStore) Versions() ([]uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]uint64, 0, len(s.roots))
+	for v := range s.roots {
+		res = append(res, v)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+func (s *MemoryStore) GetHasher() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.hasher, nil
+}
+
+func (s *MemoryStore) PutHasher(id []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hasher = id
+	return nil
+}
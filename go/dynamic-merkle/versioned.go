@@ -0,0 +1,389 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VersionedTreap is the treap stored in NodeStore. Nodes are immutable: split and merge create
+// new nodes instead of mutating existing ones, so every committed version stays queryable.
+// It has the same shape and Merkle root as Treap for the same keys and priorities.
+type VersionedTreap struct {
+	Store  NodeStore
+	Hasher Hasher
+
+	root    []byte
+	version uint64
+}
+
+// NewVersioned opens the treap at the latest committed version of the store. Nil hasher means keccak256.
+// The hasher id is saved in the store on the first open, ErrHasherMismatch is returned for another hasher later.
+func NewVersioned(store NodeStore, h Hasher) (*VersionedTreap, error) {
+	if h == nil {
+		h = Keccak256Hasher{}
+	}
+
+	t := &VersionedTreap{Store: store, Hasher: h}
+
+	stored, err := store.GetHasher()
+	if err != nil {
+		return nil, err
+	}
+
+	switch id := hasherID(h); {
+	case stored == nil:
+		if err := store.PutHasher(id); err != nil {
+			return nil, err
+		}
+	case !bytes.Equal(stored, id):
+		return nil, ErrHasherMismatch
+	}
+
+	versions, err := store.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) > 0 {
+		t.version = versions[len(versions)-1]
+		if t.root, err = store.GetRoot(t.version); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// Version returns the last committed version
+func (t *VersionedTreap) Version() uint64 {
+	return t.version
+}
+
+// Commit stores the current root as the next version
+func (t *VersionedTreap) Commit() (uint64, error) {
+	if err := t.Store.PutRoot(t.version+1, t.root); err != nil {
+		return 0, err
+	}
+
+	t.version++
+	return t.version, nil
+}
+
+func (t *VersionedTreap) Insert(key []byte, priority uint64) error {
+	node, err := t.newNode(key, priority, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	t1, t2, err := t.split(t.root, key)
+	if err != nil {
+		return err
+	}
+
+	left, err := t.merge(t1, node)
+	if err != nil {
+		return err
+	}
+
+	t.root, err = t.merge(left, t2)
+	return err
+}
+
+func (t *VersionedTreap) Remove(key []byte) error {
+	root, err := t.remove(t.root, key)
+	if err != nil {
+		return err
+	}
+
+	t.root = root
+	return nil
+}
+
+// MerkleRoot returns the root of the current (possibly uncommitted) tree
+func (t *VersionedTreap) MerkleRoot() ([]byte, error) {
+	return t.merkleHash(t.root)
+}
+
+// MerkleRootAt returns the root of the committed version
+func (t *VersionedTreap) MerkleRootAt(version uint64) ([]byte, error) {
+	id, err := t.Store.GetRoot(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.merkleHash(id)
+}
+
+// MerklePath returns the proof of key inclusion in the current tree or nil if key is not present
+func (t *VersionedTreap) MerklePath(key []byte) (*Proof, error) {
+	return t.merklePath(t.root, key)
+}
+
+// MerklePathAt returns the proof of key inclusion in the committed version or nil if key is not present
+func (t *VersionedTreap) MerklePathAt(version uint64, key []byte) (*Proof, error) {
+	id, err := t.Store.GetRoot(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.merklePath(id, key)
+}
+
+// Prune removes versions before the given one and all nodes that are not reachable
+// from the remaining versions and the current tree. The latest committed version is never removed.
+func (t *VersionedTreap) Prune(before uint64) error {
+	if before > t.version {
+		before = t.version
+	}
+
+	versions, err := t.Store.Versions()
+	if err != nil {
+		return err
+	}
+
+	roots := [][]byte{t.root}
+	for _, v := range versions {
+		if v < before {
+			if err := t.Store.DeleteRoot(v); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		id, err := t.Store.GetRoot(v)
+		if err != nil {
+			return err
+		}
+
+		roots = append(roots, id)
+	}
+
+	reachable := make(map[string]struct{})
+	for len(roots) > 0 {
+		id := roots[len(roots)-1]
+		roots = roots[:len(roots)-1]
+
+		if _, ok := reachable[string(id)]; ok || len(id) == 0 {
+			continue
+		}
+
+		reachable[string(id)] = struct{}{}
+
+		node, err := t.Store.GetNode(id)
+		if err != nil {
+			return err
+		}
+
+		roots = append(roots, node.Left, node.Right)
+	}
+
+	ids, err := t.Store.NodeIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, ok := reachable[string(id)]; !ok {
+			if err := t.Store.DeleteNode(id); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c, ok := t.Store.(Compactor); ok {
+		return c.Compact()
+	}
+
+	return nil
+}
+
+func (t *VersionedTreap) merklePath(id, key []byte) (*Proof, error) {
+	proof := &Proof{}
+
+	for len(id) > 0 {
+		node, err := t.Store.GetNode(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Equal(node.Key, key) {
+			proof.Priority = node.Priority
+			if proof.Left, err = t.merkleHash(node.Left); err != nil {
+				return nil, err
+			}
+
+			if proof.Right, err = t.merkleHash(node.Right); err != nil {
+				return nil, err
+			}
+
+			return proof, nil
+		}
+
+		step := ProofStep{Key: node.Key, Priority: node.Priority}
+
+		next, sibling := node.Left, node.Right
+		if bytes.Compare(node.Key, key) < 0 {
+			step.Right = true
+			next, sibling = node.Right, node.Left
+		}
+
+		if step.Sibling, err = t.merkleHash(sibling); err != nil {
+			return nil, err
+		}
+
+		proof.Path = append(proof.Path, step)
+		id = next
+	}
+
+	return nil, nil
+}
+
+func (t *VersionedTreap) split(id, key []byte) (left, right []byte, err error) {
+	if len(id) == 0 {
+		return nil, nil, nil
+	}
+
+	node, err := t.Store.GetNode(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if bytes.Compare(node.Key, key) < 0 {
+		t1, t2, err := t.split(node.Right, key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		res, err := t.newNode(node.Key, node.Priority, node.Left, t1)
+		return res, t2, err
+	}
+
+	t1, t2, err := t.split(node.Left, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := t.newNode(node.Key, node.Priority, t2, node.Right)
+	return t1, res, err
+}
+
+func (t *VersionedTreap) merge(id1, id2 []byte) ([]byte, error) {
+	if len(id1) == 0 {
+		return id2, nil
+	}
+
+	if len(id2) == 0 {
+		return id1, nil
+	}
+
+	n1, err := t.Store.GetNode(id1)
+	if err != nil {
+		return nil, err
+	}
+
+	n2, err := t.Store.GetNode(id2)
+	if err != nil {
+		return nil, err
+	}
+
+	if n1.Priority > n2.Priority {
+		right, err := t.merge(n1.Right, id2)
+		if err != nil {
+			return nil, err
+		}
+
+		return t.newNode(n1.Key, n1.Priority, n1.Left, right)
+	}
+
+	left, err := t.merge(id1, n2.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.newNode(n2.Key, n2.Priority, left, n2.Right)
+}
+
+func (t *VersionedTreap) remove(id, key []byte) ([]byte, error) {
+	if len(id) == 0 {
+		return nil, nil
+	}
+
+	node, err := t.Store.GetNode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch bytes.Compare(key, node.Key) {
+	case 0:
+		return t.merge(node.Left, node.Right)
+	case -1:
+		left, err := t.remove(node.Left, key)
+		if err != nil || bytes.Equal(left, node.Left) {
+			return id, err
+		}
+
+		return t.newNode(node.Key, node.Priority, left, node.Right)
+	default:
+		right, err := t.remove(node.Right, key)
+		if err != nil || bytes.Equal(right, node.Right) {
+			return id, err
+		}
+
+		return t.newNode(node.Key, node.Priority, node.Left, right)
+	}
+}
+
+// newNode stores the node with merkle hash computed as updateNode does and returns its id
+func (t *VersionedTreap) newNode(key []byte, priority uint64, left, right []byte) ([]byte, error) {
+	leftHash, err := t.merkleHash(left)
+	if err != nil {
+		return nil, err
+	}
+
+	rightHash, err := t.merkleHash(right)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &StoredNode{
+		Key:        key,
+		Priority:   priority,
+		MerkleHash: merkleNode(t.Hasher, leftHash, rightHash, key, priority),
+		Left:       left,
+		Right:      right,
+	}
+
+	id := nodeHash(node)
+	return id, t.Store.PutNode(id, node)
+}
+
+func (t *VersionedTreap) merkleHash(id []byte) ([]byte, error) {
+	if len(id) == 0 {
+		return nil, nil
+	}
+
+	node, err := t.Store.GetNode(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.MerkleHash, nil
+}
+
+// hasherID identifies the hasher by the hash of fixed values, so the store opened with another hasher is detected
+func hasherID(h Hasher) []byte {
+	return h.Hash(priorityValue(0), emptyChild)
+}
+
+// nodeHash returns the content address of the node: keccak256(len(key) || key || priority || left || right)
+func nodeHash(node *StoredNode) []byte {
+	buf := binary.BigEndian.AppendUint32(nil, uint32(len(node.Key)))
+	buf = append(buf, node.Key...)
+	buf = binary.BigEndian.AppendUint64(buf, node.Priority)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(node.Left)))
+	buf = append(buf, node.Left...)
+	return crypto.Keccak256(buf, node.Right)
+}
@@ -0,0 +1,253 @@
+package dynamic_merkle
+
+import (
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestVersionedTreap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "treap.log")
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, s := range []NodeStore{NewMemoryStore(), store} {
+		tree := New()
+		versioned, err := NewVersioned(s, nil)
+		if err != nil {
+			panic(err)
+		}
+
+		keys := make([][]byte, 0, 60)
+		roots := make(map[uint64][]byte)
+
+		// 3 versions with 20 keys each
+		for v := 0; v < 3; v++ {
+			for i := 0; i < 20; i++ {
+				key := crypto.Keccak256([]byte{byte(v), byte(i)})
+				priority := rand.Uint64()
+
+				keys = append(keys, key)
+				tree.Insert(key, priority)
+				if err := versioned.Insert(key, priority); err != nil {
+					panic(err)
+				}
+			}
+
+			// remove one of previous keys
+			tree.Remove(keys[v*5])
+			if err := versioned.Remove(keys[v*5]); err != nil {
+				panic(err)
+			}
+
+			root, err := versioned.MerkleRoot()
+			if err != nil {
+				panic(err)
+			}
+
+			if !bytes.Equal(root, tree.MerkleRoot()) {
+				panic("versioned root is not equal to treap root")
+			}
+
+			version, err := versioned.Commit()
+			if err != nil {
+				panic(err)
+			}
+
+			roots[version] = root
+		}
+
+		// proofs for the first version
+		proof, err := versioned.MerklePathAt(1, keys[1])
+		if err != nil {
+			panic(err)
+		}
+
+		if !VerifyProof(roots[1], keys[1], proof) {
+			panic("proof for the old version is invalid")
+		}
+
+		if proof, _ := versioned.MerklePathAt(1, keys[30]); proof != nil {
+			panic("key of the next version is present in the old version")
+		}
+
+		nodes, _ := s.NodeIDs()
+
+		if err := versioned.Prune(3); err != nil {
+			panic(err)
+		}
+
+		if _, err := versioned.MerkleRootAt(1); err != ErrVersionNotFound {
+			panic("pruned version is available")
+		}
+
+		pruned, _ := s.NodeIDs()
+		if len(pruned) >= len(nodes) {
+			panic("nodes are not pruned")
+		}
+
+		root, err := versioned.MerkleRootAt(3)
+		if err != nil || !bytes.Equal(root, roots[3]) {
+			panic("invalid root after pruning")
+		}
+
+		for _, key := range keys[20:] {
+			proof, err := versioned.MerklePath(key)
+			if err != nil {
+				panic(err)
+			}
+
+			if !VerifyProof(roots[3], key, proof) {
+				panic("proof is invalid after pruning")
+			}
+		}
+	}
+
+	if err := store.Close(); err != nil {
+		panic(err)
+	}
+
+	// reopen the file store
+	store, err = OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	defer store.Close()
+
+	versioned, err := NewVersioned(store, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	if versioned.Version() != 3 {
+		panic("invalid version after reopening")
+	}
+
+	root, err := versioned.MerkleRoot()
+	if err != nil {
+		panic(err)
+	}
+
+	proof, err := versioned.MerklePath(crypto.Keccak256([]byte{2, 7}))
+	if err != nil || !VerifyProof(root, crypto.Keccak256([]byte{2, 7}), proof) {
+		panic("proof is invalid after reopening")
+	}
+}
+
+func TestVersionedTreapPruneLatest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "treap.log")
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	versioned, err := NewVersioned(store, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := versioned.Insert(crypto.Keccak256([]byte{byte(i)}), rand.Uint64()); err != nil {
+			panic(err)
+		}
+
+		if _, err := versioned.Commit(); err != nil {
+			panic(err)
+		}
+	}
+
+	root, err := versioned.MerkleRoot()
+	if err != nil {
+		panic(err)
+	}
+
+	if err := versioned.Prune(100); err != nil {
+		panic(err)
+	}
+
+	if _, err := versioned.MerkleRootAt(2); err != ErrVersionNotFound {
+		panic("old version is not pruned")
+	}
+
+	if err := store.Close(); err != nil {
+		panic(err)
+	}
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	defer store.Close()
+
+	if versioned, err = NewVersioned(store, nil); err != nil {
+		panic(err)
+	}
+
+	if versioned.Version() != 3 {
+		panic("latest version is pruned")
+	}
+
+	res, err := versioned.MerkleRoot()
+	if err != nil || !bytes.Equal(res, root) {
+		panic("invalid root after pruning")
+	}
+}
+
+func TestVersionedTreapHasher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "treap.log")
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	versioned, err := NewVersioned(store, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := versioned.Insert(crypto.Keccak256([]byte{1}), 1); err != nil {
+		panic(err)
+	}
+
+	if _, err := versioned.Commit(); err != nil {
+		panic(err)
+	}
+
+	if _, err := NewVersioned(store, PoseidonHasher{}); err != ErrHasherMismatch {
+		panic("store is opened with another hasher")
+	}
+
+	// compaction keeps the hasher id
+	if err := versioned.Prune(1); err != nil {
+		panic(err)
+	}
+
+	if err := store.Close(); err != nil {
+		panic(err)
+	}
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		panic(err)
+	}
+
+	defer store.Close()
+
+	if _, err := NewVersioned(store, PoseidonHasher{}); err != ErrHasherMismatch {
+		panic("reopened store is opened with another hasher")
+	}
+
+	if _, err := NewVersioned(store, Keccak256Hasher{}); err != nil {
+		panic(err)
+	}
+}
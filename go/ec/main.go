@@ -54,10 +54,14 @@ func (c *Curve) IsOnCurve(x, y *big.Int) bool {
 		return true
 	}
 
+	if x == nil || y == nil || x.Sign() < 0 || y.Sign() < 0 || x.Cmp(c.P) >= 0 || y.Cmp(c.P) >= 0 {
+		return false
+	}
+
 	yy := mul(y, y, c.P)
 	xxx := mul(mul(x, x, c.P), x, c.P)
 	ax := mul(c.A, x, c.P)
-	return yy.Cmp(add(xxx, ax, c.P)) == 0
+	return yy.Cmp(add(add(xxx, ax, c.P), c.B, c.P)) == 0
 }
 
 func (c *Curve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
@@ -69,6 +73,15 @@ func (c *Curve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
 		return x1, y1
 	}
 
+	if x1.Cmp(x2) == 0 {
+		// P + P is a doubling and P + (-P) is the point at infinity
+		if y1.Cmp(y2) == 0 {
+			return c.Double(x1, y1)
+		}
+
+		return nil, nil
+	}
+
 	s := div(sub(y1, y2, c.P), sub(x1, x2, c.P), c.P)
 	x = sub(sub(mul(s, s, c.P), x1, c.P), x2, c.P)
 	y = sub(mul(s, sub(x1, x, c.P), c.P), y1, c.P)
@@ -80,6 +93,10 @@ func (c *Curve) Double(x1, y1 *big.Int) (x, y *big.Int) {
 		return nil, nil
 	}
 
+	if y1.Sign() == 0 {
+		return nil, nil
+	}
+
 	s := div(add(mul(fromInt(3), mul(x1, x1, c.P), c.P), c.A, c.P), mul(fromInt(2), y1, c.P), c.P)
 	x = sub(mul(s, s, c.P), mul(fromInt(2), x1, c.P), c.P)
 	y = sub(mul(s, sub(x1, x, c.P), c.P), y1, c.P)
@@ -130,7 +147,7 @@ func (c *Curve) mult(k, x1, y1 *big.Int) (x, y *big.Int) {
 }
 
 func (c *Curve) ScalarBaseMult(k []byte) (x, y *big.Int) {
-	return c.ScalarMult(c.Gx, c.Gx, k)
+	return c.ScalarMult(c.Gx, c.Gy, k)
 }
 
 func add(x *big.Int, y *big.Int, mod *big.Int) *big.Int {
@@ -82,3 +82,77 @@ func TestECMul(t *testing.T) {
 		panic("y result is not equal")
 	}
 }
+
+func TestECBaseMul(t *testing.T) {
+	curveEth := secp256k1.S256()
+	curveOleg := SECP256K1()
+
+	k := new(big.Int).SetInt64(1234567)
+
+	xres1, yres1 := curveEth.ScalarBaseMult(k.Bytes())
+	xres2, yres2 := curveOleg.ScalarBaseMult(k.Bytes())
+
+	if xres1.Cmp(xres2) != 0 {
+		panic("x result is not equal")
+	}
+
+	if yres1.Cmp(yres2) != 0 {
+		panic("y result is not equal")
+	}
+}
+
+func TestECAddSame(t *testing.T) {
+	curveOleg := SECP256K1()
+
+	x1, y1 := curveOleg.ScalarBaseMult(big.NewInt(5).Bytes())
+
+	xres1, yres1 := curveOleg.Double(x1, y1)
+	xres2, yres2 := curveOleg.Add(x1, y1, x1, y1)
+
+	if xres1.Cmp(xres2) != 0 {
+		panic("x result is not equal")
+	}
+
+	if yres1.Cmp(yres2) != 0 {
+		panic("y result is not equal")
+	}
+
+	x, y := curveOleg.Add(x1, y1, x1, new(big.Int).Sub(curveOleg.P, y1))
+	if x != nil || y != nil {
+		panic("P + (-P) is not infinity")
+	}
+}
+
+func TestECIsOnCurve(t *testing.T) {
+	curve := SECP256K1()
+
+	if !curve.IsOnCurve(curve.Gx, curve.Gy) {
+		panic("generator is not on curve")
+	}
+
+	x, y := curve.ScalarBaseMult(big.NewInt(12345).Bytes())
+	if !curve.IsOnCurve(x, y) {
+		panic("point is not on curve")
+	}
+
+	if !curve.IsOnCurve(nil, nil) {
+		panic("infinity is not on curve")
+	}
+
+	// (1, 1) satisfies y^2 = x^3 but not y^2 = x^3 + 7
+	if curve.IsOnCurve(big.NewInt(1), big.NewInt(1)) {
+		panic("point without b coefficient is on curve")
+	}
+
+	if curve.IsOnCurve(curve.Gx, new(big.Int).Add(curve.Gy, big.NewInt(1))) {
+		panic("modified point is on curve")
+	}
+
+	if curve.IsOnCurve(new(big.Int).Add(curve.Gx, curve.P), curve.Gy) {
+		panic("unreduced point is on curve")
+	}
+
+	if curve.IsOnCurve(nil, curve.Gy) || curve.IsOnCurve(curve.Gx, nil) {
+		panic("point with nil coordinate is on curve")
+	}
+}
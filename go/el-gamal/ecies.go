@@ -0,0 +1,276 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `ecies.go` implements hybrid ECIES encryption of arbitrary length payloads:
+// ephemeral ECDH with the recipient key, HKDF-SHA256 key derivation and AEAD encryption.
+// Both Curve keys (PublicKey, PrivateKey) and bn256 G1 keys (BN256PublicKey, BN256PrivateKey) are supported.
+//
+// Envelope format:
+//
+//	version (1 byte) || curve (1 byte) || aead (1 byte) || ephemeral length (2 bytes) || ephemeral || nonce || ciphertext
+//
+// Header is authenticated as AEAD additional data together with the user provided one.
+package el_gamal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/cloudflare/bn256"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const ECIESVersion byte = 1
+
+// AEAD is the authenticated encryption used by ECIES
+type AEAD byte
+
+const (
+	AES256GCM AEAD = iota + 1
+	ChaCha20Poly1305
+)
+
+const (
+	curveSECP256K1 byte = iota + 1
+	curveBN256G1
+)
+
+const eciesHeaderSize = 5
+
+var (
+	ErrInvalidEnvelope   = errors.New("invalid ecies envelope")
+	ErrUnsupportedAEAD   = errors.New("unsupported aead")
+	ErrInvalidEphemeral  = errors.New("invalid ephemeral public key")
+	ErrCurveMismatch     = errors.New("envelope curve does not match the key")
+	ErrUnsupportedFormat = errors.New("unsupported envelope version")
+)
+
+// ECIESPublicKey is a recipient key that can be used in EncryptECIES
+type ECIESPublicKey interface {
+	curveID() byte
+	bytes() []byte
+	// ephemeral generates ephemeral key and returns its encoding with the shared secret
+	ephemeral() ([]byte, []byte, error)
+}
+
+// ECIESPrivateKey is a recipient key that can be used in DecryptECIES
+type ECIESPrivateKey interface {
+	curveID() byte
+	public() ECIESPublicKey
+	// shared returns the shared secret for the encoded ephemeral key
+	shared(ephemeral []byte) ([]byte, error)
+}
+
+// BN256PublicKey is a bn256 G1 public key d*G
+type BN256PublicKey struct {
+	*bn256.G1
+}
+
+// BN256PrivateKey is a bn256 private key
+type BN256PrivateKey struct {
+	*BN256PublicKey
+	D *big.Int
+}
+
+var (
+	_ ECIESPublicKey  = &PublicKey{}
+	_ ECIESPrivateKey = &PrivateKey{}
+	_ ECIESPublicKey  = &BN256PublicKey{}
+	_ ECIESPrivateKey = &BN256PrivateKey{}
+)
+
+func GenerateBN256PrivateKey() (*BN256PrivateKey, error) {
+	d, pub, err := bn256.RandomG1(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BN256PrivateKey{
+		BN256PublicKey: &BN256PublicKey{pub},
+		D:              d,
+	}, nil
+}
+
+// EncryptECIES encrypts the payload for the recipient key.
+// Additional data `aad` is authenticated but not encrypted, the same value should be passed to DecryptECIES.
+func EncryptECIES(pub ECIESPublicKey, payload, aad []byte, mode AEAD) ([]byte, error) {
+	ephemeral, shared, err := pub.ephemeral()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, eciesHeaderSize, eciesHeaderSize+len(ephemeral))
+	header[0] = ECIESVersion
+	header[1] = pub.curveID()
+	header[2] = byte(mode)
+	binary.BigEndian.PutUint16(header[3:], uint16(len(ephemeral)))
+	header = append(header, ephemeral...)
+
+	aead, err := eciesAEAD(mode, shared, ephemeral, pub.bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	envelope := append(header, nonce...)
+	return aead.Seal(envelope, nonce, payload, append(header[:len(header):len(header)], aad...)), nil
+}
+
+// DecryptECIES decrypts and authenticates the envelope created by EncryptECIES
+func DecryptECIES(prv ECIESPrivateKey, envelope, aad []byte) ([]byte, error) {
+	if len(envelope) < eciesHeaderSize {
+		return nil, ErrInvalidEnvelope
+	}
+
+	if envelope[0] != ECIESVersion {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if envelope[1] != prv.curveID() {
+		return nil, ErrCurveMismatch
+	}
+
+	size := int(binary.BigEndian.Uint16(envelope[3:]))
+	if len(envelope) < eciesHeaderSize+size {
+		return nil, ErrInvalidEnvelope
+	}
+
+	header := envelope[:eciesHeaderSize+size]
+	ephemeral := header[eciesHeaderSize:]
+
+	shared, err := prv.shared(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := eciesAEAD(AEAD(envelope[2]), shared, ephemeral, prv.public().bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	rest := envelope[len(header):]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, append(header[:len(header):len(header)], aad...))
+}
+
+// eciesAEAD derives the symmetric key as HKDF-SHA256(shared, salt = ephemeral || recipient, info = version || aead)
+func eciesAEAD(mode AEAD, shared, ephemeral, recipient []byte) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	kdf := hkdf.New(sha256.New, shared, salt, []byte{'e', 'c', 'i', 'e', 's', ECIESVersion, byte(mode)})
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case AES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		return cipher.NewGCM(block)
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	default:
+		return nil, ErrUnsupportedAEAD
+	}
+}
+
+func (p *PublicKey) curveID() byte {
+	return curveSECP256K1
+}
+
+func (p *PublicKey) bytes() []byte {
+	return []byte(pointKey(p.X, p.Y))
+}
+
+func (p *PublicKey) ephemeral() ([]byte, []byte, error) {
+	k, err := GeneratePrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	x, _ := Curve.ScalarMult(p.X, p.Y, k.D.Bytes())
+	if x == nil {
+		return nil, nil, ErrInvalidEphemeral
+	}
+
+	return k.PublicKey.bytes(), x.Bytes(), nil
+}
+
+func (p *PrivateKey) public() ECIESPublicKey {
+	return p.PublicKey
+}
+
+func (p *PrivateKey) shared(ephemeral []byte) ([]byte, error) {
+	size := (Curve.Params().BitSize + 7) / 8
+	if len(ephemeral) != 2*size {
+		return nil, ErrInvalidEphemeral
+	}
+
+	ex, ey := new(big.Int).SetBytes(ephemeral[:size]), new(big.Int).SetBytes(ephemeral[size:])
+	if !Curve.IsOnCurve(ex, ey) {
+		return nil, ErrInvalidEphemeral
+	}
+
+	x, _ := Curve.ScalarMult(ex, ey, p.D.Bytes())
+	if x == nil {
+		return nil, ErrInvalidEphemeral
+	}
+
+	return x.Bytes(), nil
+}
+
+func (p *BN256PublicKey) curveID() byte {
+	return curveBN256G1
+}
+
+func (p *BN256PublicKey) bytes() []byte {
+	return p.Marshal()
+}
+
+func (p *BN256PublicKey) ephemeral() ([]byte, []byte, error) {
+	k, E, err := bn256.RandomG1(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return E.Marshal(), new(bn256.G1).ScalarMult(p.G1, k).Marshal(), nil
+}
+
+func (p *BN256PrivateKey) public() ECIESPublicKey {
+	return p.BN256PublicKey
+}
+
+func (p *BN256PrivateKey) shared(ephemeral []byte) ([]byte, error) {
+	E := new(bn256.G1)
+	if _, err := E.Unmarshal(ephemeral); err != nil {
+		return nil, ErrInvalidEphemeral
+	}
+
+	// point at infinity is encoded with zeros
+	if new(big.Int).SetBytes(ephemeral).Sign() == 0 {
+		return nil, ErrInvalidEphemeral
+	}
+
+	return new(bn256.G1).ScalarMult(E, p.D).Marshal(), nil
+}
@@ -0,0 +1,69 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package el_gamal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestECIES(t *testing.T) {
+	prv, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	prvBN, err := GenerateBN256PrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	payload := bytes.Repeat([]byte("Hello world! "), 100)
+	aad := []byte("ballot #1")
+
+	keys := []struct {
+		pub ECIESPublicKey
+		prv ECIESPrivateKey
+	}{
+		{prv.PublicKey, prv},
+		{prvBN.BN256PublicKey, prvBN},
+	}
+
+	for _, key := range keys {
+		for _, mode := range []AEAD{AES256GCM, ChaCha20Poly1305} {
+			envelope, err := EncryptECIES(key.pub, payload, aad, mode)
+			if err != nil {
+				panic(err)
+			}
+
+			res, err := DecryptECIES(key.prv, envelope, aad)
+			if err != nil {
+				panic(err)
+			}
+
+			if !bytes.Equal(res, payload) {
+				panic("decrypted payload is not equal")
+			}
+
+			if _, err := DecryptECIES(key.prv, envelope, []byte("ballot #2")); err == nil {
+				panic("wrong additional data was accepted")
+			}
+
+			envelope[len(envelope)-1] ^= 1
+			if _, err := DecryptECIES(key.prv, envelope, aad); err == nil {
+				panic("modified envelope was accepted")
+			}
+		}
+	}
+
+	envelope, err := EncryptECIES(prv.PublicKey, payload, nil, AES256GCM)
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err := DecryptECIES(prvBN, envelope, nil); err != ErrCurveMismatch {
+		panic("envelope for another curve was accepted")
+	}
+}
@@ -0,0 +1,143 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `exponential.go` implements exponential (additively homomorphic) ElGamal
+// where the message m is encrypted as the point m*G. Decryption requires solving
+// the discrete logarithm of m*G that is done with baby-step giant-step for the
+// messages in [0..bound-1].
+package el_gamal
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+var ErrMessageOutOfBound = errors.New("message is out of the lookup table bound")
+
+// EncryptExp encrypts scalar m as (kG, mG + kPub)
+func EncryptExp(m *big.Int, pub *PublicKey) (*Cypher, error) {
+	mx, my := Curve.ScalarBaseMult(scalar(m))
+	return Encrypt(mx, my, pub)
+}
+
+// DecryptExp decrypts cypher into mG and finds m using the given lookup table
+func DecryptExp(cypher *Cypher, prv *PrivateKey, table *DLogTable) (*big.Int, error) {
+	mx, my := Decrypt(cypher, prv)
+	return table.Log(mx, my)
+}
+
+// Add returns cypher that decrypts into the sum of both messages
+func (c *Cypher) Add(other *Cypher) *Cypher {
+	ax, ay := Curve.Add(c.Ax, c.Ay, other.Ax, other.Ay)
+	bx, by := Curve.Add(c.Bx, c.By, other.Bx, other.By)
+	return &Cypher{ax, ay, bx, by}
+}
+
+// Sub returns cypher that decrypts into the difference of both messages
+func (c *Cypher) Sub(other *Cypher) *Cypher {
+	return c.Add(other.neg())
+}
+
+// ScalarMul returns cypher that decrypts into the message multiplied by k
+func (c *Cypher) ScalarMul(k *big.Int) *Cypher {
+	ax, ay := Curve.ScalarMult(c.Ax, c.Ay, scalar(k))
+	bx, by := Curve.ScalarMult(c.Bx, c.By, scalar(k))
+	return &Cypher{ax, ay, bx, by}
+}
+
+// Rerandomize returns a fresh cypher for the same message by adding an encryption of zero point
+func Rerandomize(cypher *Cypher, pub *PublicKey) (*Cypher, error) {
+	zero, err := Encrypt(nil, nil, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return cypher.Add(zero), nil
+}
+
+func (c *Cypher) neg() *Cypher {
+	ax, ay := negPoint(c.Ax, c.Ay)
+	bx, by := negPoint(c.Bx, c.By)
+	return &Cypher{ax, ay, bx, by}
+}
+
+// DLogTable is a precomputed baby-step giant-step table that finds m from mG for m in [0..bound-1].
+// The table is never modified after creation, so it can be shared across goroutines.
+type DLogTable struct {
+	bound *big.Int
+	step  uint64
+	baby  map[string]uint64
+	// giant step -step*G
+	gx, gy *big.Int
+}
+
+// NewDLogTable precomputes the lookup table for the messages in [0..bound-1].
+// Table contains ceil(sqrt(bound)) points, so bound = 2^32 requires 2^16 points.
+func NewDLogTable(bound uint64) *DLogTable {
+	step := uint64(math.Ceil(math.Sqrt(float64(bound))))
+	if step == 0 {
+		step = 1
+	}
+
+	table := &DLogTable{
+		bound: new(big.Int).SetUint64(bound),
+		step:  step,
+		baby:  make(map[string]uint64, step),
+	}
+
+	// j*G for j in [0..step-1]
+	var x, y *big.Int
+	for j := uint64(0); j < step; j++ {
+		table.baby[pointKey(x, y)] = j
+		x, y = Curve.Add(x, y, Curve.Params().Gx, Curve.Params().Gy)
+	}
+
+	table.gx, table.gy = negPoint(x, y)
+	return table
+}
+
+// Log returns m such that (x, y) = mG or ErrMessageOutOfBound if m is not in [0..bound-1]
+func (t *DLogTable) Log(x, y *big.Int) (*big.Int, error) {
+	for i := uint64(0); i <= t.step; i++ {
+		if j, ok := t.baby[pointKey(x, y)]; ok {
+			m := new(big.Int).SetUint64(i)
+			m.Mul(m, new(big.Int).SetUint64(t.step))
+			m.Add(m, new(big.Int).SetUint64(j))
+
+			if m.Cmp(t.bound) >= 0 {
+				break
+			}
+
+			return m, nil
+		}
+
+		// (x, y) - step*G
+		x, y = Curve.Add(x, y, t.gx, t.gy)
+	}
+
+	return nil, ErrMessageOutOfBound
+}
+
+func pointKey(x, y *big.Int) string {
+	if x == nil && y == nil {
+		return ""
+	}
+
+	size := (Curve.Params().BitSize + 7) / 8
+	return string(x.FillBytes(make([]byte, size))) + string(y.FillBytes(make([]byte, size)))
+}
+
+func negPoint(x, y *big.Int) (*big.Int, *big.Int) {
+	if x == nil && y == nil {
+		return nil, nil
+	}
+
+	return x, minus(y)
+}
+
+func scalar(k *big.Int) []byte {
+	return new(big.Int).Mod(k, Curve.Params().N).Bytes()
+}
@@ -0,0 +1,87 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package el_gamal
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestExponentialHomomorphism(t *testing.T) {
+	prv, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	table := NewDLogTable(1 << 16)
+
+	c1, err := EncryptExp(big.NewInt(1234), prv.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	c2, err := EncryptExp(big.NewInt(766), prv.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	check := func(c *Cypher, expected int64) {
+		m, err := DecryptExp(c, prv, table)
+		if err != nil {
+			panic(err)
+		}
+
+		if m.Cmp(big.NewInt(expected)) != 0 {
+			panic("decrypted message is not equal")
+		}
+	}
+
+	check(c1, 1234)
+	check(c1.Add(c2), 2000)
+	check(c1.Sub(c2), 468)
+	check(c2.Sub(c2), 0)
+	check(c2.ScalarMul(big.NewInt(3)), 2298)
+
+	c3, err := Rerandomize(c1, prv.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	if c3.Ax.Cmp(c1.Ax) == 0 {
+		panic("cypher was not re-randomized")
+	}
+
+	check(c3, 1234)
+
+	_, err = DecryptExp(c2.Sub(c1), prv, table)
+	if err != ErrMessageOutOfBound {
+		panic("expected out of bound error")
+	}
+}
+
+func TestDLogTableConcurrent(t *testing.T) {
+	table := NewDLogTable(1 << 32)
+
+	wg := sync.WaitGroup{}
+	for _, m := range []int64{0, 1, 65535, 65536, 1<<32 - 1, 3141592653} {
+		wg.Add(1)
+		go func(m int64) {
+			defer wg.Done()
+
+			x, y := Curve.ScalarBaseMult(big.NewInt(m).Bytes())
+			res, err := table.Log(x, y)
+			if err != nil {
+				panic(err)
+			}
+
+			if res.Cmp(big.NewInt(m)) != 0 {
+				panic("logarithm is not equal")
+			}
+		}(m)
+	}
+
+	wg.Wait()
+}
@@ -59,7 +59,8 @@ func Encrypt(mx, my *big.Int, pub *PublicKey) (*Cypher, error) {
 
 func Decrypt(cypher *Cypher, prv *PrivateKey) (mx *big.Int, my *big.Int) {
 	x, y := Curve.ScalarMult(cypher.Ax, cypher.Ay, prv.D.Bytes())
-	mx, my = Curve.Add(cypher.Bx, cypher.By, x, minus(y))
+	x, y = negPoint(x, y)
+	mx, my = Curve.Add(cypher.Bx, cypher.By, x, y)
 	return
 }
 
@@ -0,0 +1,88 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `mixnet.go` implements multi-stage re-encryption mix-net driver
+// where the output of every mixer is the input of the next one.
+package el_gamal
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidMixStages = errors.New("mix stages are invalid")
+	ErrNoMixers         = errors.New("mix-net should have at least one mixer")
+)
+
+// Mixer is a single mix-net server that permutes and re-randomizes the cyphers
+type Mixer interface {
+	Mix(input []*Cypher) ([]*Cypher, *ShuffleProof, error)
+}
+
+// ShuffleMixer is a Mixer that uses Shuffle under the given public key
+type ShuffleMixer struct {
+	Pub *PublicKey
+}
+
+var _ Mixer = &ShuffleMixer{}
+
+func (m *ShuffleMixer) Mix(input []*Cypher) ([]*Cypher, *ShuffleProof, error) {
+	return Shuffle(input, m.Pub)
+}
+
+// MixStage contains the output of a single mixer with the proof of correct shuffle
+type MixStage struct {
+	Output []*Cypher
+	Proof  *ShuffleProof
+}
+
+// MixNet chains several mixers under the common public key
+type MixNet struct {
+	Pub    *PublicKey
+	Mixers []Mixer
+}
+
+// Run passes the input through all mixers verifying every stage before passing it to the next mixer.
+// Mix-net without mixers is rejected, because VerifyMixNet requires at least one stage.
+func (m *MixNet) Run(input []*Cypher) ([]*MixStage, error) {
+	if len(m.Mixers) == 0 {
+		return nil, ErrNoMixers
+	}
+
+	stages := make([]*MixStage, 0, len(m.Mixers))
+	for i, mixer := range m.Mixers {
+		output, proof, err := mixer.Mix(input)
+		if err != nil {
+			return nil, fmt.Errorf("mixer %d failed: %w", i, err)
+		}
+
+		if err := VerifyShuffle(input, output, m.Pub, proof); err != nil {
+			return nil, fmt.Errorf("mixer %d: %w", i, err)
+		}
+
+		stages = append(stages, &MixStage{Output: output, Proof: proof})
+		input = output
+	}
+
+	return stages, nil
+}
+
+// VerifyMixNet verifies all stages of the mix-net for the given input cyphers
+func VerifyMixNet(input []*Cypher, stages []*MixStage, pub *PublicKey) error {
+	if len(stages) == 0 {
+		return ErrInvalidMixStages
+	}
+
+	for i, stage := range stages {
+		if err := VerifyShuffle(input, stage.Output, pub, stage.Proof); err != nil {
+			return fmt.Errorf("stage %d: %w", i, err)
+		}
+
+		input = stage.Output
+	}
+
+	return nil
+}
@@ -0,0 +1,409 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `shuffle.go` implements re-encryption shuffle of ElGamal cyphers with
+// Terelius-Wikström zero-knowledge proof of correct shuffle. The implementation follows
+// "Pseudo-Code Algorithms for Verifiable Re-Encryption Mix-Nets" by Haenni et al.
+// (https://eprint.iacr.org/2017/097) written in the additive notation.
+package el_gamal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrEmptyShuffle        = errors.New("nothing to shuffle")
+	ErrInvalidShuffleProof = errors.New("shuffle proof is invalid")
+)
+
+// Point is an elliptic curve point. Nil coordinates correspond to the point at infinity.
+type Point struct {
+	X, Y *big.Int
+}
+
+// ShuffleProof proves that output cyphers are the re-encryptions of permuted input cyphers
+type ShuffleProof struct {
+	// C is a commitment to the permutation matrix
+	C []*Point
+	// CHat is a commitment chain to the permuted challenges
+	CHat []*Point
+	// E is the Fiat-Shamir challenge
+	E *big.Int
+
+	S1, S2, S3, S4 *big.Int
+	SHat           []*big.Int
+	SPrime         []*big.Int
+}
+
+// Shuffle permutes and re-randomizes the input cyphers and proves the correctness of shuffle
+func Shuffle(input []*Cypher, pub *PublicKey) ([]*Cypher, *ShuffleProof, error) {
+	if len(input) == 0 {
+		return nil, nil, ErrEmptyShuffle
+	}
+
+	perm, err := randPermutation(len(input))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// output[i] = input[perm[i]] + (rho_i*G, rho_i*Pub)
+	rho := make([]*big.Int, len(input))
+	output := make([]*Cypher, len(input))
+	for i := range output {
+		if rho[i], err = rand.Int(rand.Reader, Curve.Params().N); err != nil {
+			return nil, nil, err
+		}
+
+		output[i] = reEncrypt(input[perm[i]], pub, rho[i])
+	}
+
+	proof, err := proveShuffle(input, output, pub, perm, rho)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output, proof, nil
+}
+
+// VerifyShuffle verifies that output cyphers are the re-encryptions of permuted input cyphers
+func VerifyShuffle(input, output []*Cypher, pub *PublicKey, proof *ShuffleProof) error {
+	n := len(input)
+	if n == 0 || len(output) != n || pub == nil || !isPoint(pub.X, pub.Y) || !validShuffleProof(proof, n) {
+		return ErrInvalidShuffleProof
+	}
+
+	for i := range input {
+		if !isCypher(input[i]) || !isCypher(output[i]) {
+			return ErrInvalidShuffleProof
+		}
+	}
+
+	h, hs := shuffleGenerators(n)
+	u := shuffleChallenges(input, output, proof.C, pub)
+	e := proof.E
+	negE := new(big.Int).Neg(e)
+
+	// cBar = sum(c_i) - sum(h_i)
+	// cHat = cHat_n - prod(u_i)*h
+	// cTilde = sum(u_i*c_i)
+	// a = sum(u_i*B_i), b = sum(u_i*A_i)
+	cBar, cTilde, a, b := &Point{}, &Point{}, &Point{}, &Point{}
+	uHat := big.NewInt(1)
+	for i := 0; i < n; i++ {
+		cBar = addPoints(cBar, proof.C[i], negate(hs[i]))
+		cTilde = addPoints(cTilde, mulPoint(proof.C[i], u[i]))
+		a = addPoints(a, mulPoint(&Point{input[i].Bx, input[i].By}, u[i]))
+		b = addPoints(b, mulPoint(&Point{input[i].Ax, input[i].Ay}, u[i]))
+		uHat.Mod(uHat.Mul(uHat, u[i]), Curve.Params().N)
+	}
+
+	cHat := addPoints(proof.CHat[n-1], negate(mulPoint(h, uHat)))
+
+	g := basePoint()
+	y := &Point{pub.X, pub.Y}
+
+	t1 := addPoints(mulPoint(g, proof.S1), mulPoint(cBar, negE))
+	t2 := addPoints(mulPoint(g, proof.S2), mulPoint(cHat, negE))
+	t3 := addPoints(mulPoint(g, proof.S3), mulPoint(cTilde, negE))
+	t41 := addPoints(mulPoint(y, new(big.Int).Neg(proof.S4)), mulPoint(a, negE))
+	t42 := addPoints(mulPoint(g, new(big.Int).Neg(proof.S4)), mulPoint(b, negE))
+
+	tHat := make([]*Point, n)
+	prev := h
+	for i := 0; i < n; i++ {
+		t3 = addPoints(t3, mulPoint(hs[i], proof.SPrime[i]))
+		t41 = addPoints(t41, mulPoint(&Point{output[i].Bx, output[i].By}, proof.SPrime[i]))
+		t42 = addPoints(t42, mulPoint(&Point{output[i].Ax, output[i].Ay}, proof.SPrime[i]))
+
+		tHat[i] = addPoints(mulPoint(g, proof.SHat[i]), mulPoint(prev, proof.SPrime[i]), mulPoint(proof.CHat[i], negE))
+		prev = proof.CHat[i]
+	}
+
+	expected := shuffleChallenge(input, output, pub, proof.C, proof.CHat, append([]*Point{t1, t2, t3, t41, t42}, tHat...))
+	if expected.Cmp(e) != 0 {
+		return ErrInvalidShuffleProof
+	}
+
+	return nil
+}
+
+func proveShuffle(input, output []*Cypher, pub *PublicKey, perm []int, rho []*big.Int) (*ShuffleProof, error) {
+	n := len(input)
+	N := Curve.Params().N
+
+	h, hs := shuffleGenerators(n)
+	g := basePoint()
+	y := &Point{pub.X, pub.Y}
+
+	randoms := func(count int) ([]*big.Int, error) {
+		res := make([]*big.Int, count)
+		for i := range res {
+			var err error
+			if res[i], err = rand.Int(rand.Reader, N); err != nil {
+				return nil, err
+			}
+		}
+
+		return res, nil
+	}
+
+	// permutation commitment c_{perm[i]} = r_{perm[i]}*G + h_i
+	r, err := randoms(n)
+	if err != nil {
+		return nil, err
+	}
+
+	c := make([]*Point, n)
+	for i := 0; i < n; i++ {
+		c[perm[i]] = addPoints(mulPoint(g, r[perm[i]]), hs[i])
+	}
+
+	u := shuffleChallenges(input, output, c, pub)
+	uPrime := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		uPrime[i] = u[perm[i]]
+	}
+
+	// commitment chain cHat_i = rHat_i*G + uPrime_i*cHat_{i-1}, cHat_{-1} = h
+	rHat, err := randoms(n)
+	if err != nil {
+		return nil, err
+	}
+
+	cHat := make([]*Point, n)
+	prev := h
+	for i := 0; i < n; i++ {
+		cHat[i] = addPoints(mulPoint(g, rHat[i]), mulPoint(prev, uPrime[i]))
+		prev = cHat[i]
+	}
+
+	// v_i = prod(uPrime_j) for j > i
+	v := make([]*big.Int, n)
+	v[n-1] = big.NewInt(1)
+	for i := n - 1; i > 0; i-- {
+		v[i-1] = new(big.Int).Mod(new(big.Int).Mul(uPrime[i], v[i]), N)
+	}
+
+	rBar, rHatSum, rTilde, rPrime := big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0)
+	for i := 0; i < n; i++ {
+		rBar.Add(rBar, r[i])
+		rHatSum.Add(rHatSum, new(big.Int).Mul(rHat[i], v[i]))
+		rTilde.Add(rTilde, new(big.Int).Mul(r[i], u[i]))
+		rPrime.Add(rPrime, new(big.Int).Mul(rho[i], uPrime[i]))
+	}
+
+	omega, err := randoms(4)
+	if err != nil {
+		return nil, err
+	}
+
+	omegaHat, err := randoms(n)
+	if err != nil {
+		return nil, err
+	}
+
+	omegaPrime, err := randoms(n)
+	if err != nil {
+		return nil, err
+	}
+
+	t1 := mulPoint(g, omega[0])
+	t2 := mulPoint(g, omega[1])
+	t3 := mulPoint(g, omega[2])
+	t41 := mulPoint(y, new(big.Int).Neg(omega[3]))
+	t42 := mulPoint(g, new(big.Int).Neg(omega[3]))
+
+	tHat := make([]*Point, n)
+	prev = h
+	for i := 0; i < n; i++ {
+		t3 = addPoints(t3, mulPoint(hs[i], omegaPrime[i]))
+		t41 = addPoints(t41, mulPoint(&Point{output[i].Bx, output[i].By}, omegaPrime[i]))
+		t42 = addPoints(t42, mulPoint(&Point{output[i].Ax, output[i].Ay}, omegaPrime[i]))
+
+		tHat[i] = addPoints(mulPoint(g, omegaHat[i]), mulPoint(prev, omegaPrime[i]))
+		prev = cHat[i]
+	}
+
+	e := shuffleChallenge(input, output, pub, c, cHat, append([]*Point{t1, t2, t3, t41, t42}, tHat...))
+
+	response := func(omega, secret *big.Int) *big.Int {
+		return new(big.Int).Mod(new(big.Int).Add(omega, new(big.Int).Mul(e, secret)), N)
+	}
+
+	proof := &ShuffleProof{
+		C:      c,
+		CHat:   cHat,
+		E:      e,
+		S1:     response(omega[0], rBar),
+		S2:     response(omega[1], rHatSum),
+		S3:     response(omega[2], rTilde),
+		S4:     response(omega[3], rPrime),
+		SHat:   make([]*big.Int, n),
+		SPrime: make([]*big.Int, n),
+	}
+
+	for i := 0; i < n; i++ {
+		proof.SHat[i] = response(omegaHat[i], rHat[i])
+		proof.SPrime[i] = response(omegaPrime[i], uPrime[i])
+	}
+
+	return proof, nil
+}
+
+func reEncrypt(cypher *Cypher, pub *PublicKey, r *big.Int) *Cypher {
+	ax, ay := Curve.ScalarBaseMult(scalar(r))
+	bx, by := Curve.ScalarMult(pub.X, pub.Y, scalar(r))
+	return cypher.Add(&Cypher{ax, ay, bx, by})
+}
+
+// randPermutation returns uniformly random permutation using Fisher-Yates shuffle
+func randPermutation(n int) ([]int, error) {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	for i := n - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+
+		perm[i], perm[j.Int64()] = perm[j.Int64()], perm[i]
+	}
+
+	return perm, nil
+}
+
+// shuffleGenerators returns independent generators h, h_0..h_{n-1} with unknown discrete logarithms
+func shuffleGenerators(n int) (*Point, []*Point) {
+	hs := make([]*Point, n)
+	for i := range hs {
+		hs[i] = hashToPoint([]byte("el-gamal shuffle generator"), uint64(i))
+	}
+
+	return hashToPoint([]byte("el-gamal shuffle base")), hs
+}
+
+// hashToPoint maps data to the curve point using try-and-increment method.
+// Curve is expected to have the equation y^2 = x^3 + b.
+func hashToPoint(data []byte, index ...uint64) *Point {
+	P := Curve.Params().P
+
+	for counter := uint64(0); ; counter++ {
+		h := sha256.New()
+		h.Write(data)
+		for _, i := range index {
+			h.Write(binary.BigEndian.AppendUint64(nil, i))
+		}
+		h.Write(binary.BigEndian.AppendUint64(nil, counter))
+
+		x := new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), P)
+		rhs := new(big.Int).Exp(x, big.NewInt(3), P)
+		rhs.Mod(rhs.Add(rhs, Curve.Params().B), P)
+
+		y := new(big.Int).ModSqrt(rhs, P)
+		if y != nil && Curve.IsOnCurve(x, y) {
+			return &Point{x, y}
+		}
+	}
+}
+
+// shuffleChallenges returns the challenges u_i = H(input, output, c, pub, i)
+func shuffleChallenges(input, output []*Cypher, c []*Point, pub *PublicKey) []*big.Int {
+	statement := hashShuffleStatement(input, output, pub, c)
+
+	u := make([]*big.Int, len(input))
+	for i := range u {
+		h := sha256.New()
+		h.Write(statement)
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+		u[i] = new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), Curve.Params().N)
+	}
+
+	return u
+}
+
+// shuffleChallenge returns the challenge e = H(input, output, c, pub, cHat, t)
+func shuffleChallenge(input, output []*Cypher, pub *PublicKey, c, cHat, t []*Point) *big.Int {
+	h := sha256.New()
+	h.Write(hashShuffleStatement(input, output, pub, c, cHat))
+	for _, p := range t {
+		h.Write([]byte(pointKey(p.X, p.Y)))
+	}
+
+	return new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), Curve.Params().N)
+}
+
+func hashShuffleStatement(input, output []*Cypher, pub *PublicKey, points ...[]*Point) []byte {
+	h := sha256.New()
+	h.Write([]byte(pointKey(pub.X, pub.Y)))
+	for _, list := range [][]*Cypher{input, output} {
+		for _, c := range list {
+			h.Write([]byte(pointKey(c.Ax, c.Ay)))
+			h.Write([]byte(pointKey(c.Bx, c.By)))
+		}
+	}
+
+	for _, list := range points {
+		for _, p := range list {
+			h.Write([]byte(pointKey(p.X, p.Y)))
+		}
+	}
+
+	return h.Sum(nil)
+}
+
+func validShuffleProof(proof *ShuffleProof, n int) bool {
+	if proof == nil || len(proof.C) != n || len(proof.CHat) != n || len(proof.SHat) != n || len(proof.SPrime) != n {
+		return false
+	}
+
+	for _, s := range append([]*big.Int{proof.E, proof.S1, proof.S2, proof.S3, proof.S4}, append(proof.SHat, proof.SPrime...)...) {
+		if s == nil {
+			return false
+		}
+	}
+
+	for _, p := range append(append([]*Point{}, proof.C...), proof.CHat...) {
+		if p == nil || !isPoint(p.X, p.Y) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isCypher checks that both cypher points are on the Curve
+func isCypher(c *Cypher) bool {
+	return c != nil && isPoint(c.Ax, c.Ay) && isPoint(c.Bx, c.By)
+}
+
+func basePoint() *Point {
+	return &Point{Curve.Params().Gx, Curve.Params().Gy}
+}
+
+func addPoints(points ...*Point) *Point {
+	var x, y *big.Int
+	for _, p := range points {
+		x, y = Curve.Add(x, y, p.X, p.Y)
+	}
+
+	return &Point{x, y}
+}
+
+func negate(p *Point) *Point {
+	x, y := negPoint(p.X, p.Y)
+	return &Point{x, y}
+}
+
+func mulPoint(p *Point, k *big.Int) *Point {
+	x, y := Curve.ScalarMult(p.X, p.Y, scalar(k))
+	return &Point{x, y}
+}
@@ -0,0 +1,168 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package el_gamal
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+)
+
+func TestShuffle(t *testing.T) {
+	prv, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	input := encryptVotes(prv.PublicKey, 1, 2, 3, 4, 5)
+
+	output, proof, err := Shuffle(input, prv.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := VerifyShuffle(input, output, prv.PublicKey, proof); err != nil {
+		panic(err)
+	}
+
+	checkVotes(prv, output, 1, 2, 3, 4, 5)
+
+	// replace one output with a fresh encryption of another vote
+	forged := append([]*Cypher{}, output...)
+	forged[0] = encryptVotes(prv.PublicKey, 6)[0]
+	if err := VerifyShuffle(input, forged, prv.PublicKey, proof); err != ErrInvalidShuffleProof {
+		panic("forged shuffle was accepted")
+	}
+
+	proof.S1 = new(big.Int).Add(proof.S1, big.NewInt(1))
+	if err := VerifyShuffle(input, output, prv.PublicKey, proof); err != ErrInvalidShuffleProof {
+		panic("modified proof was accepted")
+	}
+}
+
+func TestShuffleInvalidPoints(t *testing.T) {
+	prv, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	input := encryptVotes(prv.PublicKey, 1, 2, 3)
+
+	output, proof, err := Shuffle(input, prv.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	one := big.NewInt(1)
+	check := func(input, output []*Cypher, proof *ShuffleProof, msg string) {
+		if err := VerifyShuffle(input, output, prv.PublicKey, proof); err != ErrInvalidShuffleProof {
+			panic(msg)
+		}
+	}
+
+	// B with nil x and non-nil y
+	broken := append([]*Cypher{}, input...)
+	broken[0] = &Cypher{Ax: input[0].Ax, Ay: input[0].Ay, By: input[0].By}
+	check(broken, output, proof, "input with half-nil B was accepted")
+
+	broken = append([]*Cypher{}, output...)
+	broken[1] = &Cypher{Ax: output[1].Ax, Ay: output[1].Ay, Bx: output[1].Bx, By: new(big.Int).Add(output[1].By, one)}
+	check(input, broken, proof, "output with B not on curve was accepted")
+
+	broken[1] = nil
+	check(input, broken, proof, "nil output was accepted")
+
+	c := proof.C[0]
+	proof.C[0] = &Point{c.X, new(big.Int).Add(c.Y, one)}
+	check(input, output, proof, "proof with C not on curve was accepted")
+	proof.C[0] = c
+
+	cHat := proof.CHat[0]
+	proof.CHat[0] = &Point{nil, cHat.Y}
+	check(input, output, proof, "proof with half-nil CHat was accepted")
+	proof.CHat[0] = cHat
+
+	if err := VerifyShuffle(input, output, prv.PublicKey, proof); err != nil {
+		panic(err)
+	}
+}
+
+func TestMixNet(t *testing.T) {
+	prv, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	input := encryptVotes(prv.PublicKey, 10, 20, 30, 40)
+
+	net := &MixNet{
+		Pub: prv.PublicKey,
+		Mixers: []Mixer{
+			&ShuffleMixer{Pub: prv.PublicKey},
+			&ShuffleMixer{Pub: prv.PublicKey},
+			&ShuffleMixer{Pub: prv.PublicKey},
+		},
+	}
+
+	stages, err := net.Run(input)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := VerifyMixNet(input, stages, prv.PublicKey); err != nil {
+		panic(err)
+	}
+
+	checkVotes(prv, stages[len(stages)-1].Output, 10, 20, 30, 40)
+
+	stages[1].Output, stages[2].Output = stages[2].Output, stages[1].Output
+	if err := VerifyMixNet(input, stages, prv.PublicKey); !errors.Is(err, ErrInvalidShuffleProof) {
+		panic("invalid mix-net was accepted")
+	}
+
+	if _, err := (&MixNet{Pub: prv.PublicKey}).Run(input); err != ErrNoMixers {
+		panic("mix-net without mixers was run")
+	}
+
+	if err := VerifyMixNet(input, nil, prv.PublicKey); err != ErrInvalidMixStages {
+		panic("empty mix stages were accepted")
+	}
+}
+
+func encryptVotes(pub *PublicKey, votes ...int64) []*Cypher {
+	res := make([]*Cypher, 0, len(votes))
+	for _, v := range votes {
+		c, err := EncryptExp(big.NewInt(v), pub)
+		if err != nil {
+			panic(err)
+		}
+
+		res = append(res, c)
+	}
+
+	return res
+}
+
+func checkVotes(prv *PrivateKey, cyphers []*Cypher, votes ...int64) {
+	table := NewDLogTable(1 << 10)
+
+	res := make([]int64, 0, len(cyphers))
+	for _, c := range cyphers {
+		m, err := DecryptExp(c, prv, table)
+		if err != nil {
+			panic(err)
+		}
+
+		res = append(res, m.Int64())
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	for i := range votes {
+		if res[i] != votes[i] {
+			panic("decrypted votes are not equal")
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `threshold.go` implements t-of-n threshold ElGamal decryption. The private key
+// is split with Shamir secret sharing over Curve.N and every party publishes the partial
+// decryption d_i*A together with Chaum-Pedersen proof that log_G(D_i*G) = log_A(d_i*A).
+// Any t valid partial decryptions are combined with Lagrange interpolation in the exponent.
+package el_gamal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrInvalidThreshold  = errors.New("threshold should be in [1..n]")
+	ErrInvalidShareProof = errors.New("partial decryption proof is invalid")
+	ErrNotEnoughShares   = errors.New("not enough valid partial decryptions")
+)
+
+// KeyShare is a private key share of the party with index `Index`.
+// Embedded PublicKey is the verification key d_i*G.
+type KeyShare struct {
+	*PublicKey
+	Index *big.Int
+	D     *big.Int
+}
+
+// ThresholdKey contains the common public key and verification keys of all parties
+type ThresholdKey struct {
+	*PublicKey
+	T            int
+	Verification map[string]*PublicKey
+}
+
+// DecryptionShare is a partial decryption d_i*A with the proof of its correctness
+type DecryptionShare struct {
+	Index *big.Int
+	X, Y  *big.Int
+	Proof *ChaumPedersenProof
+}
+
+// ChaumPedersenProof proves the knowledge of x such that P = xG and Q = xA
+type ChaumPedersenProof struct {
+	T1x, T1y *big.Int
+	T2x, T2y *big.Int
+	Z        *big.Int
+}
+
+// SplitPrivateKey splits private key into n shares where any t shares are enough for decryption.
+// Party indexes are 1..n.
+func SplitPrivateKey(prv *PrivateKey, t, n int) ([]*KeyShare, *ThresholdKey, error) {
+	if t < 1 || t > n {
+		return nil, nil, ErrInvalidThreshold
+	}
+
+	// f(x) = d + a_1*x + ... + a_{t-1}*x^{t-1}
+	coefficients := []*big.Int{prv.D}
+	for i := 1; i < t; i++ {
+		a, err := rand.Int(rand.Reader, Curve.Params().N)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		coefficients = append(coefficients, a)
+	}
+
+	key := &ThresholdKey{
+		PublicKey:    prv.PublicKey,
+		T:            t,
+		Verification: make(map[string]*PublicKey, n),
+	}
+
+	shares := make([]*KeyShare, 0, n)
+	for i := 1; i <= n; i++ {
+		index := big.NewInt(int64(i))
+		d := evalPoly(coefficients, index)
+		x, y := Curve.ScalarBaseMult(d.Bytes())
+
+		share := &KeyShare{
+			PublicKey: &PublicKey{X: x, Y: y},
+			Index:     index,
+			D:         d,
+		}
+
+		key.Verification[index.String()] = share.PublicKey
+		shares = append(shares, share)
+	}
+
+	return shares, key, nil
+}
+
+// PartialDecrypt creates the partial decryption d_i*A of cypher
+func PartialDecrypt(cypher *Cypher, share *KeyShare) (*DecryptionShare, error) {
+	x, y := Curve.ScalarMult(cypher.Ax, cypher.Ay, share.D.Bytes())
+
+	proof, err := proveChaumPedersen(share.D, share.X, share.Y, cypher.Ax, cypher.Ay, x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecryptionShare{
+		Index: share.Index,
+		X:     x,
+		Y:     y,
+		Proof: proof,
+	}, nil
+}
+
+// VerifyShare verifies that the partial decryption was created with the party's key share
+func (k *ThresholdKey) VerifyShare(cypher *Cypher, share *DecryptionShare) error {
+	if share == nil || share.Index == nil || !isPoint(share.X, share.Y) || share.Proof == nil {
+		return ErrInvalidShareProof
+	}
+
+	pub, ok := k.Verification[share.Index.String()]
+	if !ok {
+		return ErrInvalidShareProof
+	}
+
+	if !verifyChaumPedersen(share.Proof, pub.X, pub.Y, cypher.Ax, cypher.Ay, share.X, share.Y) {
+		return ErrInvalidShareProof
+	}
+
+	return nil
+}
+
+// Combine verifies the partial decryptions and returns decrypted message point B - sum(l_i*d_i*A).
+// Invalid and duplicate shares are rejected, first t valid shares are used for interpolation.
+// Nil share or share without index returns ErrInvalidShareProof.
+func (k *ThresholdKey) Combine(cypher *Cypher, shares []*DecryptionShare) (mx, my *big.Int, err error) {
+	valid := make([]*DecryptionShare, 0, k.T)
+	indexes := make([]*big.Int, 0, k.T)
+	seen := make(map[string]struct{}, k.T)
+
+	for _, share := range shares {
+		if len(valid) == k.T {
+			break
+		}
+
+		if share == nil || share.Index == nil {
+			return nil, nil, ErrInvalidShareProof
+		}
+
+		if _, ok := seen[share.Index.String()]; ok {
+			continue
+		}
+
+		if err := k.VerifyShare(cypher, share); err != nil {
+			continue
+		}
+
+		seen[share.Index.String()] = struct{}{}
+		valid = append(valid, share)
+		indexes = append(indexes, share.Index)
+	}
+
+	if len(valid) < k.T {
+		return nil, nil, ErrNotEnoughShares
+	}
+
+	// dA = sum(l_i * d_i*A)
+	var x, y *big.Int
+	for _, share := range valid {
+		lx, ly := Curve.ScalarMult(share.X, share.Y, lagrange(share.Index, indexes).Bytes())
+		x, y = Curve.Add(x, y, lx, ly)
+	}
+
+	x, y = negPoint(x, y)
+	mx, my = Curve.Add(cypher.Bx, cypher.By, x, y)
+	return
+}
+
+// CombineExp combines the partial decryptions of exponential ElGamal cypher and finds m using the given lookup table
+func (k *ThresholdKey) CombineExp(cypher *Cypher, shares []*DecryptionShare, table *DLogTable) (*big.Int, error) {
+	mx, my, err := k.Combine(cypher, shares)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.Log(mx, my)
+}
+
+// lagrange returns Lagrange coefficient at zero l_i = prod(j/(j-i)) for j != i
+func lagrange(i *big.Int, indexes []*big.Int) *big.Int {
+	N := Curve.Params().N
+
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+	for _, j := range indexes {
+		if j.Cmp(i) == 0 {
+			continue
+		}
+
+		num.Mod(num.Mul(num, j), N)
+		den.Mod(den.Mul(den, new(big.Int).Sub(j, i)), N)
+	}
+
+	return num.Mod(num.Mul(num, den.ModInverse(den, N)), N)
+}
+
+func evalPoly(coefficients []*big.Int, x *big.Int) *big.Int {
+	res := big.NewInt(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		res.Mul(res, x)
+		res.Add(res, coefficients[i])
+		res.Mod(res, Curve.Params().N)
+	}
+
+	return res
+}
+
+func proveChaumPedersen(d, px, py, ax, ay, qx, qy *big.Int) (*ChaumPedersenProof, error) {
+	r, err := rand.Int(rand.Reader, Curve.Params().N)
+	if err != nil {
+		return nil, err
+	}
+
+	t1x, t1y := Curve.ScalarBaseMult(r.Bytes())
+	t2x, t2y := Curve.ScalarMult(ax, ay, r.Bytes())
+
+	e := challenge(px, py, ax, ay, qx, qy, t1x, t1y, t2x, t2y)
+
+	// z = r + e*d
+	z := new(big.Int).Mod(new(big.Int).Add(r, new(big.Int).Mul(e, d)), Curve.Params().N)
+
+	return &ChaumPedersenProof{
+		T1x: t1x, T1y: t1y,
+		T2x: t2x, T2y: t2y,
+		Z: z,
+	}, nil
+}
+
+func verifyChaumPedersen(proof *ChaumPedersenProof, px, py, ax, ay, qx, qy *big.Int) bool {
+	if !isPoint(proof.T1x, proof.T1y) || !isPoint(proof.T2x, proof.T2y) || proof.Z == nil {
+		return false
+	}
+
+	e := challenge(px, py, ax, ay, qx, qy, proof.T1x, proof.T1y, proof.T2x, proof.T2y)
+
+	// zG == T1 + eP
+	lx, ly := Curve.ScalarBaseMult(scalar(proof.Z))
+	rx, ry := Curve.ScalarMult(px, py, e.Bytes())
+	rx, ry = Curve.Add(proof.T1x, proof.T1y, rx, ry)
+	if pointKey(lx, ly) != pointKey(rx, ry) {
+		return false
+	}
+
+	// zA == T2 + eQ
+	lx, ly = Curve.ScalarMult(ax, ay, scalar(proof.Z))
+	rx, ry = Curve.ScalarMult(qx, qy, e.Bytes())
+	rx, ry = Curve.Add(proof.T2x, proof.T2y, rx, ry)
+	return pointKey(lx, ly) == pointKey(rx, ry)
+}
+
+func challenge(points ...*big.Int) *big.Int {
+	h := sha256.New()
+	for i := 0; i+1 < len(points); i += 2 {
+		h.Write([]byte(pointKey(points[i], points[i+1])))
+	}
+
+	return new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), Curve.Params().N)
+}
+
+// isPoint checks that the point is not an infinity and lies on the Curve
+func isPoint(x, y *big.Int) bool {
+	return x != nil && y != nil && Curve.IsOnCurve(x, y)
+}
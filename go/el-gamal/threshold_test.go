@@ -0,0 +1,158 @@
+// Package el_gamal
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package el_gamal
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestThresholdDecryption(t *testing.T) {
+	prv, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	shares, key, err := SplitPrivateKey(prv, 3, 5)
+	if err != nil {
+		panic(err)
+	}
+
+	point, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	msg := point.PublicKey
+
+	cypher, err := Encrypt(msg.X, msg.Y, key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	partial := make([]*DecryptionShare, 0, len(shares))
+	for _, share := range shares {
+		p, err := PartialDecrypt(cypher, share)
+		if err != nil {
+			panic(err)
+		}
+
+		partial = append(partial, p)
+	}
+
+	x, y, err := key.Combine(cypher, []*DecryptionShare{partial[4], partial[1], partial[2]})
+	if err != nil {
+		panic(err)
+	}
+
+	if msg.X.Cmp(x) != 0 || msg.Y.Cmp(y) != 0 {
+		panic("decrypted message is not equal")
+	}
+
+	_, _, err = key.Combine(cypher, []*DecryptionShare{partial[0], partial[3]})
+	if err != ErrNotEnoughShares {
+		panic("expected not enough shares error")
+	}
+}
+
+func TestThresholdInvalidShare(t *testing.T) {
+	prv, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	shares, key, err := SplitPrivateKey(prv, 2, 3)
+	if err != nil {
+		panic(err)
+	}
+
+	table := NewDLogTable(1 << 10)
+
+	cypher, err := EncryptExp(big.NewInt(42), key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	partial := make([]*DecryptionShare, 0, len(shares))
+	for _, share := range shares {
+		p, err := PartialDecrypt(cypher, share)
+		if err != nil {
+			panic(err)
+		}
+
+		partial = append(partial, p)
+	}
+
+	// party 1 publishes a wrong decryption share with a proof for its own key
+	fake, err := PartialDecrypt(cypher, &KeyShare{PublicKey: shares[0].PublicKey, Index: shares[0].Index, D: big.NewInt(7)})
+	if err != nil {
+		panic(err)
+	}
+
+	if err := key.VerifyShare(cypher, fake); err != ErrInvalidShareProof {
+		panic("invalid share was accepted")
+	}
+
+	if _, err := key.CombineExp(cypher, []*DecryptionShare{fake, partial[1]}, table); err != ErrNotEnoughShares {
+		panic("invalid share was used in combination")
+	}
+
+	if _, _, err := key.Combine(cypher, []*DecryptionShare{partial[1], nil, partial[2]}); err != ErrInvalidShareProof {
+		panic("nil share was accepted")
+	}
+
+	if _, _, err := key.Combine(cypher, []*DecryptionShare{{X: partial[1].X, Y: partial[1].Y}, partial[2]}); err != ErrInvalidShareProof {
+		panic("share without index was accepted")
+	}
+
+	m, err := key.CombineExp(cypher, []*DecryptionShare{fake, partial[1], partial[1], partial[2]}, table)
+	if err != nil {
+		panic(err)
+	}
+
+	if m.Cmp(big.NewInt(42)) != 0 {
+		panic("decrypted message is not equal")
+	}
+}
+
+func TestThresholdShareNotOnCurve(t *testing.T) {
+	prv, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	shares, key, err := SplitPrivateKey(prv, 2, 3)
+	if err != nil {
+		panic(err)
+	}
+
+	cypher, err := EncryptExp(big.NewInt(42), key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	share, err := PartialDecrypt(cypher, shares[0])
+	if err != nil {
+		panic(err)
+	}
+
+	x, y := share.X, share.Y
+	share.Y = new(big.Int).Add(y, big.NewInt(1))
+	if err := key.VerifyShare(cypher, share); err != ErrInvalidShareProof {
+		panic("share point not on curve was accepted")
+	}
+
+	share.X, share.Y = x, y
+	share.Proof.T1y = new(big.Int).Add(share.Proof.T1y, big.NewInt(1))
+	if err := key.VerifyShare(cypher, share); err != ErrInvalidShareProof {
+		panic("proof point T1 not on curve was accepted")
+	}
+
+	share.Proof.T1y.Sub(share.Proof.T1y, big.NewInt(1))
+	share.Proof.T2x = new(big.Int).Add(share.Proof.T2x, big.NewInt(1))
+	if err := key.VerifyShare(cypher, share); err != ErrInvalidShareProof {
+		panic("proof point T2 not on curve was accepted")
+	}
+}
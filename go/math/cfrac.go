@@ -0,0 +1,54 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidFraction = errors.New("fraction should have non-negative numerator and positive denominator")
+
+// ContinuedFraction returns the continued fraction expansion [a_0; a_1, ..., a_k] of a/b for a >= 0, b > 0
+// More information: https://en.wikipedia.org/wiki/Continued_fraction
+func ContinuedFraction(a, b *big.Int) ([]*big.Int, error) {
+	if b.Sign() <= 0 || a.Sign() < 0 {
+		return nil, ErrInvalidFraction
+	}
+
+	a, b = new(big.Int).Set(a), new(big.Int).Set(b)
+
+	var res []*big.Int
+	for b.Sign() != 0 {
+		q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+		res = append(res, q)
+		a, b = b, r
+	}
+
+	return res, nil
+}
+
+// Convergents returns the convergents h_i/k_i of the continued fraction:
+// h_i = a_i*h_{i-1} + h_{i-2}, k_i = a_i*k_{i-1} + k_{i-2}
+func Convergents(cf []*big.Int) (h, k []*big.Int) {
+	h = make([]*big.Int, 0, len(cf))
+	k = make([]*big.Int, 0, len(cf))
+
+	h1, h2 := big.NewInt(1), big.NewInt(0)
+	k1, k2 := big.NewInt(0), big.NewInt(1)
+
+	for _, a := range cf {
+		hi := new(big.Int).Add(new(big.Int).Mul(a, h1), h2)
+		ki := new(big.Int).Add(new(big.Int).Mul(a, k1), k2)
+
+		h = append(h, hi)
+		k = append(k, ki)
+
+		h1, h2 = hi, h1
+		k1, k2 = ki, k1
+	}
+
+	return h, k
+}
@@ -0,0 +1,48 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestContinuedFraction(t *testing.T) {
+	// 415/93 = [4; 2, 6, 7]
+	cf, err := ContinuedFraction(big.NewInt(415), big.NewInt(93))
+	if err != nil {
+		panic(err)
+	}
+
+	expected := ints(4, 2, 6, 7)
+
+	if len(cf) != len(expected) {
+		panic("invalid continued fraction length")
+	}
+
+	for i := range cf {
+		if cf[i].Cmp(expected[i]) != 0 {
+			panic("invalid continued fraction")
+		}
+	}
+
+	// 4/1, 9/2, 58/13, 415/93
+	h, k := Convergents(cf)
+	expectedH, expectedK := ints(4, 9, 58, 415), ints(1, 2, 13, 93)
+
+	for i := range h {
+		if h[i].Cmp(expectedH[i]) != 0 || k[i].Cmp(expectedK[i]) != 0 {
+			panic("invalid convergent")
+		}
+	}
+
+	if _, err := ContinuedFraction(big.NewInt(1), big.NewInt(0)); err != ErrInvalidFraction {
+		panic("fraction with zero denominator was accepted")
+	}
+
+	if _, err := ContinuedFraction(big.NewInt(-1), big.NewInt(2)); err != ErrInvalidFraction {
+		panic("negative fraction was accepted")
+	}
+}
@@ -0,0 +1,199 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrInconsistentCRT = errors.New("system of congruences has no solution")
+	ErrInvalidModuli   = errors.New("invalid moduli")
+	ErrNotCoprime      = errors.New("moduli should be pairwise coprime")
+)
+
+// CRT solves the system x = a_i (mod m_i) using Chinese Remainder Theorem.
+// Moduli may be not coprime: the congruences are merged one by one and ErrInconsistentCRT
+// is returned if a_i != a_j (mod gcd(m_i, m_j)) for some i, j.
+// Returns the smallest non-negative solution x and the modulus lcm(m_0, ..., m_k) of the solution.
+// More information: https://en.wikipedia.org/wiki/Chinese_remainder_theorem
+func CRT(a, m []*big.Int) (x *big.Int, mod *big.Int, err error) {
+	if len(a) != len(m) || len(m) == 0 {
+		return nil, nil, ErrInvalidModuli
+	}
+
+	x = big.NewInt(0)
+	mod = big.NewInt(1)
+
+	for i := range m {
+		if m[i].Sign() <= 0 {
+			return nil, nil, ErrInvalidModuli
+		}
+
+		// x + mod*t = a_i (mod m_i) => mod*t = a_i - x (mod m_i)
+		g := new(big.Int).GCD(nil, nil, mod, m[i])
+		diff := new(big.Int).Sub(a[i], x)
+
+		if new(big.Int).Mod(diff, g).Sign() != 0 {
+			return nil, nil, ErrInconsistentCRT
+		}
+
+		// t = (diff/g) * (mod/g)^-1 (mod m_i/g)
+		mg := new(big.Int).Div(m[i], g)
+		t := new(big.Int).Div(diff, g)
+		if mg.Cmp(big.NewInt(1)) != 0 {
+			t = mul(t, new(big.Int).ModInverse(new(big.Int).Div(mod, g), mg), mg)
+		} else {
+			t.SetInt64(0)
+		}
+
+		x.Add(x, new(big.Int).Mul(mod, t))
+		mod.Mul(mod, mg)
+		x.Mod(x, mod)
+	}
+
+	return x, mod, nil
+}
+
+// MixedRadix returns the mixed-radix representation of x = a_i (mod m_i) for pairwise coprime moduli:
+// x = v_0 + v_1*m_0 + v_2*m_0*m_1 + ... + v_k*m_0*...*m_{k-1}, where 0 <= v_i < m_i
+func MixedRadix(a, m []*big.Int) ([]*big.Int, error) {
+	if len(a) != len(m) || len(m) == 0 {
+		return nil, ErrInvalidModuli
+	}
+
+	inv, err := garnerInverses(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return mixedRadix(a, m, inv), nil
+}
+
+// Garner reconstructs x = a_i (mod m_i) for pairwise coprime moduli using Garner's algorithm
+// More information: https://en.wikipedia.org/wiki/Mixed_radix#Application
+func Garner(a, m []*big.Int) (*big.Int, error) {
+	v, err := MixedRadix(a, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromMixedRadix(v, m), nil
+}
+
+// garnerInverses returns inv[i][j] = m_j^-1 (mod m_i) for j < i
+func garnerInverses(m []*big.Int) ([][]*big.Int, error) {
+	inv := make([][]*big.Int, len(m))
+	for i := range m {
+		if m[i].Cmp(big.NewInt(1)) <= 0 {
+			return nil, ErrInvalidModuli
+		}
+
+		inv[i] = make([]*big.Int, i)
+		for j := 0; j < i; j++ {
+			inv[i][j] = new(big.Int).ModInverse(m[j], m[i])
+			if inv[i][j] == nil {
+				return nil, ErrNotCoprime
+			}
+		}
+	}
+
+	return inv, nil
+}
+
+func mixedRadix(a, m []*big.Int, inv [][]*big.Int) []*big.Int {
+	v := make([]*big.Int, len(m))
+	for i := range m {
+		// v_i = (...((a_i - v_0)*m_0^-1 - v_1)*m_1^-1 - ... - v_{i-1})*m_{i-1}^-1 (mod m_i)
+		v[i] = new(big.Int).Mod(a[i], m[i])
+		for j := 0; j < i; j++ {
+			v[i] = mul(sub(v[i], v[j], m[i]), inv[i][j], m[i])
+		}
+	}
+
+	return v
+}
+
+func fromMixedRadix(v, m []*big.Int) *big.Int {
+	x := big.NewInt(0)
+	for i := len(v) - 1; i >= 0; i-- {
+		x.Mul(x, m[i])
+		x.Add(x, v[i])
+	}
+
+	return x
+}
+
+// Residues is the representation of a number in the residue number system
+type Residues []*big.Int
+
+// RNS is a residue number system with pairwise coprime moduli.
+// Numbers in [0..M-1] are represented by the residues modulo every m_i, where M = m_0*...*m_k.
+// More information: https://en.wikipedia.org/wiki/Residue_number_system
+type RNS struct {
+	Moduli []*big.Int
+	M      *big.Int
+
+	inv [][]*big.Int
+}
+
+func NewRNS(moduli ...*big.Int) (*RNS, error) {
+	if len(moduli) == 0 {
+		return nil, ErrInvalidModuli
+	}
+
+	inv, err := garnerInverses(moduli)
+	if err != nil {
+		return nil, err
+	}
+
+	M := big.NewInt(1)
+	for _, m := range moduli {
+		M.Mul(M, m)
+	}
+
+	return &RNS{
+		Moduli: moduli,
+		M:      M,
+		inv:    inv,
+	}, nil
+}
+
+// ToResidues returns the residues of x modulo every m_i
+func (r *RNS) ToResidues(x *big.Int) Residues {
+	res := make(Residues, len(r.Moduli))
+	for i, m := range r.Moduli {
+		res[i] = new(big.Int).Mod(x, m)
+	}
+
+	return res
+}
+
+// FromResidues reconstructs x in [0..M-1] using Garner's algorithm with precomputed inverses
+func (r *RNS) FromResidues(x Residues) *big.Int {
+	return fromMixedRadix(mixedRadix(x, r.Moduli, r.inv), r.Moduli)
+}
+
+func (r *RNS) Add(x, y Residues) Residues {
+	return r.apply(x, y, add)
+}
+
+func (r *RNS) Sub(x, y Residues) Residues {
+	return r.apply(x, y, sub)
+}
+
+func (r *RNS) Mul(x, y Residues) Residues {
+	return r.apply(x, y, mul)
+}
+
+func (r *RNS) apply(x, y Residues, f func(x, y, mod *big.Int) *big.Int) Residues {
+	res := make(Residues, len(r.Moduli))
+	for i, m := range r.Moduli {
+		res[i] = f(x[i], y[i], m)
+	}
+
+	return res
+}
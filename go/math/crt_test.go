@@ -0,0 +1,112 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func ints(vals ...int64) []*big.Int {
+	res := make([]*big.Int, 0, len(vals))
+	for _, v := range vals {
+		res = append(res, big.NewInt(v))
+	}
+
+	return res
+}
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		a   []*big.Int
+		m   []*big.Int
+		x   *big.Int
+		mod *big.Int
+		err error
+	}{
+		{
+			a:   ints(2, 3, 2),
+			m:   ints(3, 5, 7),
+			x:   big.NewInt(23),
+			mod: big.NewInt(105),
+		},
+		{
+			a:   ints(3, 5),
+			m:   ints(4, 6),
+			x:   big.NewInt(11),
+			mod: big.NewInt(12),
+		},
+		{
+			a:   ints(1, 7, 7),
+			m:   ints(6, 10, 15),
+			x:   big.NewInt(7),
+			mod: big.NewInt(30),
+		},
+		{
+			a:   ints(3, 4),
+			m:   ints(4, 6),
+			err: ErrInconsistentCRT,
+		},
+	}
+
+	for i, test := range tests {
+		x, mod, err := CRT(test.a, test.m)
+		if err != test.err {
+			panic(fmt.Sprintf("test case %d failed: unexpected error %v", i, err))
+		}
+
+		if err == nil && (x.Cmp(test.x) != 0 || mod.Cmp(test.mod) != 0) {
+			panic(fmt.Sprintf("test case %d failed: got %s mod %s", i, x, mod))
+		}
+	}
+}
+
+func TestGarner(t *testing.T) {
+	m := ints(3, 5, 7, 11)
+	x := big.NewInt(1000)
+
+	v, err := MixedRadix(ints(1, 0, 6, 10), m)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := Garner(ints(1, 0, 6, 10), m)
+	if err != nil {
+		panic(err)
+	}
+
+	if res.Cmp(x) != 0 || fromMixedRadix(v, m).Cmp(x) != 0 {
+		panic("reconstructed value is not equal")
+	}
+
+	if _, err := Garner(ints(1, 2), ints(4, 6)); err != ErrNotCoprime {
+		panic("not coprime moduli were accepted")
+	}
+}
+
+func TestRNS(t *testing.T) {
+	rns, err := NewRNS(ints(65537, 65539, 65543)...)
+	if err != nil {
+		panic(err)
+	}
+
+	x, y := big.NewInt(123456789), big.NewInt(987654)
+
+	sum := rns.FromResidues(rns.Add(rns.ToResidues(x), rns.ToResidues(y)))
+	if sum.Cmp(new(big.Int).Add(x, y)) != 0 {
+		panic("sum is not equal")
+	}
+
+	diff := rns.FromResidues(rns.Sub(rns.ToResidues(x), rns.ToResidues(y)))
+	if diff.Cmp(new(big.Int).Sub(x, y)) != 0 {
+		panic("difference is not equal")
+	}
+
+	prod := rns.FromResidues(rns.Mul(rns.ToResidues(x), rns.ToResidues(y)))
+	if prod.Cmp(new(big.Int).Mod(new(big.Int).Mul(x, y), rns.M)) != 0 {
+		panic("product is not equal")
+	}
+}
@@ -0,0 +1,348 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math/big"
+)
+
+const (
+	// maxBSGSTable limits the size of baby-step table, so BSGS supports bounds up to 2^48
+	maxBSGSTable = 1 << 24
+	// bsgsSubgroupBound is the largest prime subgroup order solved with BSGS in Pohlig-Hellman
+	bsgsSubgroupBound = 1 << 32
+	kangarooAttempts  = 8
+	rhoAttempts       = 16
+)
+
+var (
+	ErrNoDiscreteLog = errors.New("discrete logarithm not found")
+	ErrBoundTooLarge = errors.New("bound is too large")
+	ErrInvalidBound  = errors.New("invalid bound")
+	ErrInvalidOrder  = errors.New("group order should be positive")
+)
+
+// BSGS finds x in [0..bound-1] such that base^x = target using baby-step giant-step algorithm
+// More information: https://en.wikipedia.org/wiki/Baby-step_giant-step
+func BSGS(g Group, base, target GroupElement, bound *big.Int) (*big.Int, error) {
+	if bound.Sign() <= 0 {
+		return nil, ErrInvalidBound
+	}
+
+	m := new(big.Int).Sqrt(bound)
+	if new(big.Int).Mul(m, m).Cmp(bound) < 0 {
+		m.Add(m, big.NewInt(1))
+	}
+
+	if m.Cmp(big.NewInt(maxBSGSTable)) > 0 {
+		return nil, ErrBoundTooLarge
+	}
+
+	step := m.Int64()
+
+	// base^j for j in [0..m-1]
+	table := make(map[string]int64, step)
+	cur := g.Identity()
+	for j := int64(0); j < step; j++ {
+		key := string(g.Bytes(cur))
+		if _, ok := table[key]; !ok {
+			table[key] = j
+		}
+
+		cur = g.Mul(cur, base)
+	}
+
+	// giant step base^-m
+	factor := g.Inv(cur)
+	gamma := target
+	for i := int64(0); i <= step; i++ {
+		if j, ok := table[string(g.Bytes(gamma))]; ok {
+			x := new(big.Int).Add(new(big.Int).Mul(big.NewInt(i), m), big.NewInt(j))
+			if x.Cmp(bound) < 0 {
+				return x, nil
+			}
+
+			break
+		}
+
+		gamma = g.Mul(gamma, factor)
+	}
+
+	return nil, ErrNoDiscreteLog
+}
+
+// PollardRho finds x in [0..order-1] such that base^x = target where order is the order of base.
+// Works best for prime order. Uses Floyd cycle detection with three-set partition of the group.
+// More information: https://en.wikipedia.org/wiki/Pollard%27s_rho_algorithm_for_logarithms
+func PollardRho(g Group, base, target GroupElement, order *big.Int) (*big.Int, error) {
+	if order.Sign() <= 0 {
+		return nil, ErrInvalidBound
+	}
+
+	if order.Cmp(big.NewInt(4)) < 0 {
+		return BSGS(g, base, target, order)
+	}
+
+	// for the element outside of <base> the cycle is never useful, so the walk is limited
+	limit := new(big.Int).Mul(new(big.Int).Sqrt(order), big.NewInt(8))
+	limit.Add(limit, big.NewInt(1000))
+
+	// x = base^a * target^b
+	step := func(x GroupElement, a, b *big.Int) (GroupElement, *big.Int, *big.Int) {
+		switch partition(g.Bytes(x), 3, 0) {
+		case 0:
+			return g.Mul(x, target), a, add(b, big.NewInt(1), order)
+		case 1:
+			return g.Mul(x, x), mul(a, big.NewInt(2), order), mul(b, big.NewInt(2), order)
+		default:
+			return g.Mul(x, base), add(a, big.NewInt(1), order), b
+		}
+	}
+
+	for attempt := 0; attempt < rhoAttempts; attempt++ {
+		a1, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, err
+		}
+
+		b1, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, err
+		}
+
+		x1 := g.Mul(g.Exp(base, a1), g.Exp(target, b1))
+		x2, a2, b2 := x1, a1, b1
+
+		for i := big.NewInt(0); i.Cmp(limit) < 0; i.Add(i, big.NewInt(1)) {
+			x1, a1, b1 = step(x1, a1, b1)
+			x2, a2, b2 = step(x2, a2, b2)
+			x2, a2, b2 = step(x2, a2, b2)
+
+			if bytes.Equal(g.Bytes(x1), g.Bytes(x2)) {
+				break
+			}
+		}
+
+		if !bytes.Equal(g.Bytes(x1), g.Bytes(x2)) {
+			return nil, ErrNoDiscreteLog
+		}
+
+		// a1 + b1*x = a2 + b2*x => (b1 - b2)*x = a2 - a1 (mod order)
+		if x, ok := solveLinear(g, base, target, sub(b1, b2, order), sub(a2, a1, order), order); ok {
+			return x, nil
+		}
+	}
+
+	return nil, ErrNoDiscreteLog
+}
+
+// PollardKangaroo finds x in [a..b] such that base^x = target using Pollard's lambda algorithm
+// More information: https://en.wikipedia.org/wiki/Pollard%27s_kangaroo_algorithm
+func PollardKangaroo(g Group, base, target GroupElement, a, b *big.Int) (*big.Int, error) {
+	width := new(big.Int).Sub(b, a)
+	if width.Sign() < 0 {
+		return nil, ErrInvalidBound
+	}
+
+	// jumps are 2^0..2^{k-1} with the mean close to sqrt(width)/2
+	mean := new(big.Int).Rsh(new(big.Int).Sqrt(width), 1)
+	k := 1
+	for new(big.Int).Div(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(k)), big.NewInt(1)), big.NewInt(int64(k))).Cmp(mean) < 0 {
+		k++
+	}
+
+	jumps := make([]*big.Int, k)
+	powers := make([]GroupElement, k)
+	for i := range jumps {
+		jumps[i] = new(big.Int).Lsh(big.NewInt(1), uint(i))
+		powers[i] = g.Exp(base, jumps[i])
+	}
+
+	tameSteps := new(big.Int).Add(new(big.Int).Mul(mean, big.NewInt(4)), big.NewInt(1))
+
+	for attempt := uint64(0); attempt < kangarooAttempts; attempt++ {
+		// tame kangaroo starts at base^b and sets the trap
+		tame := g.Exp(base, b)
+		tameDist := big.NewInt(0)
+		for i := big.NewInt(0); i.Cmp(tameSteps) < 0; i.Add(i, big.NewInt(1)) {
+			j := partition(g.Bytes(tame), uint64(k), attempt)
+			tameDist.Add(tameDist, jumps[j])
+			tame = g.Mul(tame, powers[j])
+		}
+
+		trap := g.Bytes(tame)
+
+		// wild kangaroo starts at target and jumps until it passes the trap
+		wild := target
+		wildDist := big.NewInt(0)
+		maxDist := new(big.Int).Add(width, tameDist)
+		for wildDist.Cmp(maxDist) <= 0 {
+			key := g.Bytes(wild)
+			if bytes.Equal(key, trap) {
+				// x + wildDist = b + tameDist
+				x := new(big.Int).Sub(new(big.Int).Add(b, tameDist), wildDist)
+				if x.Cmp(a) >= 0 && bytes.Equal(g.Bytes(g.Exp(base, x)), g.Bytes(target)) {
+					return x, nil
+				}
+
+				break
+			}
+
+			j := partition(key, uint64(k), attempt)
+			wildDist.Add(wildDist, jumps[j])
+			wild = g.Mul(wild, powers[j])
+		}
+	}
+
+	return nil, ErrNoDiscreteLog
+}
+
+// PohligHellman finds x such that base^x = target where groupOrder is a multiple of the base order.
+// The order is factorized and logarithms in prime order subgroups are combined with CRT.
+// Returns x modulo the order of base.
+// More information: https://en.wikipedia.org/wiki/Pohlig%E2%80%93Hellman_algorithm
+func PohligHellman(g Group, base, target GroupElement, groupOrder *big.Int) (*big.Int, error) {
+	if groupOrder.Sign() <= 0 {
+		return nil, ErrInvalidOrder
+	}
+
+	factors := Factorize(groupOrder)
+	identity := g.Bytes(g.Identity())
+
+	// reduce the group order to the order of base
+	order := new(big.Int).Set(groupOrder)
+	for _, f := range factors {
+		for f.E > 0 {
+			reduced := new(big.Int).Div(order, f.P)
+			if !bytes.Equal(g.Bytes(g.Exp(base, reduced)), identity) {
+				break
+			}
+
+			order = reduced
+			f.E--
+		}
+	}
+
+	residues := make([]*big.Int, 0, len(factors))
+	moduli := make([]*big.Int, 0, len(factors))
+
+	for _, f := range factors {
+		if f.E == 0 {
+			continue
+		}
+
+		pe := new(big.Int).Exp(f.P, big.NewInt(int64(f.E)), nil)
+		cofactor := new(big.Int).Div(order, pe)
+
+		// gi and hi are in the subgroup of order p^e
+		gi := g.Exp(base, cofactor)
+		hi := g.Exp(target, cofactor)
+
+		// gamma has order p
+		gamma := g.Exp(gi, new(big.Int).Exp(f.P, big.NewInt(int64(f.E-1)), nil))
+
+		x := big.NewInt(0)
+		pk := big.NewInt(1)
+		for k := 0; k < f.E; k++ {
+			// hk = (gi^-x * hi)^(p^(e-1-k))
+			hk := g.Mul(g.Inv(g.Exp(gi, x)), hi)
+			hk = g.Exp(hk, new(big.Int).Exp(f.P, big.NewInt(int64(f.E-1-k)), nil))
+
+			d, err := primeOrderLog(g, gamma, hk, f.P)
+			if err != nil {
+				return nil, err
+			}
+
+			x.Add(x, new(big.Int).Mul(d, pk))
+			pk.Mul(pk, f.P)
+		}
+
+		residues = append(residues, x)
+		moduli = append(moduli, pe)
+	}
+
+	if len(moduli) == 0 {
+		// base is the identity
+		if bytes.Equal(g.Bytes(target), identity) {
+			return big.NewInt(0), nil
+		}
+
+		return nil, ErrNoDiscreteLog
+	}
+
+	x, _, err := CRT(residues, moduli)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(g.Bytes(g.Exp(base, x)), g.Bytes(target)) {
+		return nil, ErrNoDiscreteLog
+	}
+
+	return x, nil
+}
+
+// DiscreteLogZp finds x such that base^x = target (mod p) using Pohlig-Hellman with the group order Phi(p)
+func DiscreteLogZp(base, target, p *big.Int) (*big.Int, error) {
+	var order *big.Int
+	if p.ProbablyPrime(primalityRounds) {
+		order = new(big.Int).Sub(p, big.NewInt(1))
+	} else {
+		order = Phi(new(big.Int).Set(p))
+	}
+
+	return PohligHellman(&ZpGroup{P: p}, new(big.Int).Mod(base, p), new(big.Int).Mod(target, p), order)
+}
+
+func primeOrderLog(g Group, base, target GroupElement, p *big.Int) (*big.Int, error) {
+	if p.Cmp(big.NewInt(bsgsSubgroupBound)) <= 0 {
+		return BSGS(g, base, target, p)
+	}
+
+	return PollardRho(g, base, target, p)
+}
+
+// solveLinear finds x such that r*x = s (mod n) and base^x = target
+func solveLinear(g Group, base, target GroupElement, r, s, n *big.Int) (*big.Int, bool) {
+	d := new(big.Int).GCD(nil, nil, r, n)
+	if d.Sign() == 0 || new(big.Int).Mod(s, d).Sign() != 0 {
+		return nil, false
+	}
+
+	// too many candidates to check
+	if d.Cmp(big.NewInt(maxBSGSTable)) > 0 {
+		return nil, false
+	}
+
+	nd := new(big.Int).Div(n, d)
+	x := big.NewInt(0)
+	if nd.Cmp(big.NewInt(1)) != 0 {
+		x = mul(new(big.Int).Div(s, d), new(big.Int).ModInverse(new(big.Int).Div(r, d), nd), nd)
+	}
+
+	expected := g.Bytes(target)
+	for i := int64(0); i < d.Int64(); i++ {
+		if bytes.Equal(g.Bytes(g.Exp(base, x)), expected) {
+			return x, true
+		}
+
+		x = new(big.Int).Add(x, nd)
+	}
+
+	return nil, false
+}
+
+// partition maps the element encoding into [0..n-1]
+func partition(data []byte, n uint64, salt uint64) uint64 {
+	h := fnv.New64a()
+	h.Write(binary.BigEndian.AppendUint64(nil, salt))
+	h.Write(data)
+	return h.Sum64() % n
+}
@@ -0,0 +1,150 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/cloudflare/bn256"
+	"github.com/olegfomenko/crypto/go/ec"
+)
+
+// safePrime returns p = 2q + 1 where q is a prime of the given bit size
+func safePrime(bits int) (p, q *big.Int) {
+	for {
+		q, err := rand.Prime(rand.Reader, bits)
+		if err != nil {
+			panic(err)
+		}
+
+		p := new(big.Int).Add(new(big.Int).Lsh(q, 1), big.NewInt(1))
+		if p.ProbablyPrime(20) {
+			return p, q
+		}
+	}
+}
+
+func TestBSGS(t *testing.T) {
+	g := &CurveGroup{Curve: ec.SECP256K1()}
+	base := &CurvePoint{g.Curve.Params().Gx, g.Curve.Params().Gy}
+
+	x := big.NewInt(987654)
+	res, err := BSGS(g, base, g.Exp(base, x), big.NewInt(1<<20))
+	if err != nil {
+		panic(err)
+	}
+
+	if res.Cmp(x) != 0 {
+		panic("logarithm is not equal")
+	}
+
+	if _, err := BSGS(g, base, g.Exp(base, x), big.NewInt(1000)); err != ErrNoDiscreteLog {
+		panic("logarithm out of bound was found")
+	}
+}
+
+func TestPollardRho(t *testing.T) {
+	p, q := safePrime(32)
+	g := &ZpGroup{P: p}
+
+	// 4 is a square, so it has order q
+	base := big.NewInt(4)
+
+	x, err := rand.Int(rand.Reader, q)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := PollardRho(g, base, g.Exp(base, x), q)
+	if err != nil {
+		panic(err)
+	}
+
+	if res.Cmp(x) != 0 {
+		panic("logarithm is not equal")
+	}
+}
+
+func TestPollardKangaroo(t *testing.T) {
+	g := &BN256Group{}
+	base := new(bn256.G1).ScalarBaseMult(big.NewInt(1))
+
+	a := big.NewInt(1 << 40)
+	b := new(big.Int).Add(a, big.NewInt(1<<24))
+	x := new(big.Int).Add(a, big.NewInt(12345678))
+
+	res, err := PollardKangaroo(g, base, g.Exp(base, x), a, b)
+	if err != nil {
+		panic(err)
+	}
+
+	if res.Cmp(x) != 0 {
+		panic("logarithm is not equal")
+	}
+}
+
+func TestPohligHellman(t *testing.T) {
+	// p - 1 = 2^10 * 3^7 * 5^4 * 7^3 * 11 * 13 * k
+	smooth := big.NewInt(1 << 10 * 2187 * 625 * 343 * 11 * 13)
+
+	p := new(big.Int)
+	for k := int64(2); ; k += 2 {
+		p.Add(new(big.Int).Mul(smooth, big.NewInt(k)), big.NewInt(1))
+		if p.ProbablyPrime(20) {
+			break
+		}
+	}
+
+	base := big.NewInt(3)
+	x, err := rand.Int(rand.Reader, p)
+	if err != nil {
+		panic(err)
+	}
+
+	target := new(big.Int).Exp(base, x, p)
+
+	res, err := DiscreteLogZp(base, target, p)
+	if err != nil {
+		panic(err)
+	}
+
+	if new(big.Int).Exp(base, res, p).Cmp(target) != 0 {
+		panic("logarithm is not valid")
+	}
+}
+
+func TestFactorize(t *testing.T) {
+	p, _ := rand.Prime(rand.Reader, 40)
+	q, _ := rand.Prime(rand.Reader, 40)
+
+	n := new(big.Int).Mul(p, q)
+	n.Mul(n, big.NewInt(8*9))
+
+	factors := Factorize(n)
+	res := big.NewInt(1)
+	for _, f := range factors {
+		if !f.P.ProbablyPrime(20) {
+			panic("factor is not prime")
+		}
+
+		res.Mul(res, new(big.Int).Exp(f.P, big.NewInt(int64(f.E)), nil))
+	}
+
+	if res.Cmp(n) != 0 {
+		panic("factorization is not valid")
+	}
+
+	for _, n := range []int64{0, -6} {
+		if Factorize(big.NewInt(n)) != nil {
+			panic("factorization of non-positive value")
+		}
+	}
+
+	if len(Factorize(big.NewInt(1))) != 0 {
+		panic("factorization of one is not empty")
+	}
+}
@@ -0,0 +1,158 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sort"
+)
+
+var ErrFactorNotFound = errors.New("factor not found")
+
+const (
+	primalityRounds    = 20
+	trialDivisionBound = 1000
+)
+
+// PrimePower represents P^E
+type PrimePower struct {
+	P *big.Int
+	E int
+}
+
+// Factorize returns the prime factorization of n > 0 sorted by primes, nil for n <= 0.
+// Small factors are found with trial division and the rest with Pollard's rho method.
+func Factorize(n *big.Int) []*PrimePower {
+	if n.Sign() <= 0 {
+		return nil
+	}
+
+	n = new(big.Int).Set(n)
+	factors := make(map[string]*PrimePower)
+
+	addFactor := func(p *big.Int) {
+		if f, ok := factors[p.String()]; ok {
+			f.E++
+			return
+		}
+
+		factors[p.String()] = &PrimePower{P: new(big.Int).Set(p), E: 1}
+	}
+
+	for i := int64(2); i < trialDivisionBound && n.Cmp(big.NewInt(1)) > 0; i++ {
+		p := big.NewInt(i)
+		for new(big.Int).Mod(n, p).Sign() == 0 {
+			addFactor(p)
+			n.Div(n, p)
+		}
+	}
+
+	stack := []*big.Int{n}
+	for len(stack) > 0 {
+		m := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if m.Cmp(big.NewInt(1)) == 0 {
+			continue
+		}
+
+		if m.ProbablyPrime(primalityRounds) {
+			addFactor(m)
+			continue
+		}
+
+		d := pollardRho(m)
+		stack = append(stack, d, new(big.Int).Div(m, d))
+	}
+
+	res := make([]*PrimePower, 0, len(factors))
+	for _, f := range factors {
+		res = append(res, f)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].P.Cmp(res[j].P) < 0 })
+	return res
+}
+
+// pollardRho returns a non-trivial divisor of composite n using f(x) = x^2 + c (mod n)
+// More information: https://en.wikipedia.org/wiki/Pollard%27s_rho_algorithm
+func pollardRho(n *big.Int) *big.Int {
+	if n.Bit(0) == 0 {
+		return big.NewInt(2)
+	}
+
+	for {
+		c, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			panic(err)
+		}
+
+		x, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			panic(err)
+		}
+
+		f := func(x *big.Int) *big.Int {
+			return add(mul(x, x, n), c, n)
+		}
+
+		y := new(big.Int).Set(x)
+		d := big.NewInt(1)
+
+		for d.Cmp(big.NewInt(1)) == 0 {
+			x = f(x)
+			y = f(f(y))
+			d = new(big.Int).GCD(nil, nil, n, new(big.Int).Abs(new(big.Int).Sub(x, y)))
+		}
+
+		if d.Cmp(n) != 0 {
+			return d
+		}
+	}
+}
+
+// FermatFactor returns n = p*q (p >= q) for odd n by searching a such that a^2 - n is a perfect square b^2,
+// so p = a + b, q = a - b. It is fast when |p - q| is small compared to n^(1/4).
+// Returns ErrFactorNotFound if no factors were found in the given number of steps.
+// More information: https://en.wikipedia.org/wiki/Fermat%27s_factorization_method
+func FermatFactor(n *big.Int, steps int) (p, q *big.Int, err error) {
+	if n.Sign() <= 0 || n.Bit(0) == 0 {
+		if n.Cmp(big.NewInt(2)) > 0 {
+			return new(big.Int).Rsh(n, 1), big.NewInt(2), nil
+		}
+
+		return nil, nil, ErrFactorNotFound
+	}
+
+	// a = ceil(sqrt(n))
+	a := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(a, a).Cmp(n) < 0 {
+		a.Add(a, big.NewInt(1))
+	}
+
+	b2 := new(big.Int).Sub(new(big.Int).Mul(a, a), n)
+
+	for i := 0; i < steps; i++ {
+		if IsSquare(b2) {
+			b := new(big.Int).Sqrt(b2)
+			p, q = new(big.Int).Add(a, b), new(big.Int).Sub(a, b)
+
+			if q.Cmp(big.NewInt(1)) == 0 {
+				return nil, nil, ErrFactorNotFound
+			}
+
+			return p, q, nil
+		}
+
+		// (a + 1)^2 - n = b2 + 2a + 1
+		b2.Add(b2, new(big.Int).Lsh(a, 1))
+		b2.Add(b2, big.NewInt(1))
+		a.Add(a, big.NewInt(1))
+	}
+
+	return nil, nil, ErrFactorNotFound
+}
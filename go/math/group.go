@@ -0,0 +1,129 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/cloudflare/bn256"
+)
+
+// GroupElement is an element of the Group. Concrete type depends on the group implementation.
+type GroupElement interface{}
+
+// Group is a finite cyclic group written multiplicatively
+type Group interface {
+	Identity() GroupElement
+	Mul(a, b GroupElement) GroupElement
+	Exp(a GroupElement, k *big.Int) GroupElement
+	Inv(a GroupElement) GroupElement
+	// Bytes returns the canonical encoding: elements are equal only if their encodings are equal
+	Bytes(a GroupElement) []byte
+}
+
+// ZpGroup is the multiplicative group Z_p^* with *big.Int elements
+type ZpGroup struct {
+	P *big.Int
+}
+
+var _ Group = &ZpGroup{}
+
+func (g *ZpGroup) Identity() GroupElement {
+	return big.NewInt(1)
+}
+
+func (g *ZpGroup) Mul(a, b GroupElement) GroupElement {
+	return mul(a.(*big.Int), b.(*big.Int), g.P)
+}
+
+func (g *ZpGroup) Exp(a GroupElement, k *big.Int) GroupElement {
+	if k.Sign() < 0 {
+		return new(big.Int).Exp(g.Inv(a).(*big.Int), new(big.Int).Neg(k), g.P)
+	}
+
+	return new(big.Int).Exp(a.(*big.Int), k, g.P)
+}
+
+func (g *ZpGroup) Inv(a GroupElement) GroupElement {
+	return new(big.Int).ModInverse(a.(*big.Int), g.P)
+}
+
+func (g *ZpGroup) Bytes(a GroupElement) []byte {
+	return new(big.Int).Mod(a.(*big.Int), g.P).Bytes()
+}
+
+// CurvePoint is an element of CurveGroup. Nil coordinates correspond to the point at infinity.
+type CurvePoint struct {
+	X, Y *big.Int
+}
+
+// CurveGroup is the group of elliptic curve points with *CurvePoint elements.
+// Curve should represent the point at infinity with nil coordinates like ec.Curve does.
+type CurveGroup struct {
+	Curve elliptic.Curve
+}
+
+var _ Group = &CurveGroup{}
+
+func (g *CurveGroup) Identity() GroupElement {
+	return &CurvePoint{}
+}
+
+func (g *CurveGroup) Mul(a, b GroupElement) GroupElement {
+	p, q := a.(*CurvePoint), b.(*CurvePoint)
+	x, y := g.Curve.Add(p.X, p.Y, q.X, q.Y)
+	return &CurvePoint{x, y}
+}
+
+func (g *CurveGroup) Exp(a GroupElement, k *big.Int) GroupElement {
+	p := a.(*CurvePoint)
+	x, y := g.Curve.ScalarMult(p.X, p.Y, new(big.Int).Mod(k, g.Curve.Params().N).Bytes())
+	return &CurvePoint{x, y}
+}
+
+func (g *CurveGroup) Inv(a GroupElement) GroupElement {
+	p := a.(*CurvePoint)
+	if p.X == nil && p.Y == nil {
+		return p
+	}
+
+	return &CurvePoint{p.X, new(big.Int).Mod(new(big.Int).Neg(p.Y), g.Curve.Params().P)}
+}
+
+func (g *CurveGroup) Bytes(a GroupElement) []byte {
+	p := a.(*CurvePoint)
+	if p.X == nil && p.Y == nil {
+		return nil
+	}
+
+	size := (g.Curve.Params().BitSize + 7) / 8
+	return append(p.X.FillBytes(make([]byte, size)), p.Y.FillBytes(make([]byte, size))...)
+}
+
+// BN256Group is the bn256 G1 group with *bn256.G1 elements
+type BN256Group struct{}
+
+var _ Group = &BN256Group{}
+
+func (g *BN256Group) Identity() GroupElement {
+	return new(bn256.G1).ScalarBaseMult(big.NewInt(0))
+}
+
+func (g *BN256Group) Mul(a, b GroupElement) GroupElement {
+	return new(bn256.G1).Add(a.(*bn256.G1), b.(*bn256.G1))
+}
+
+func (g *BN256Group) Exp(a GroupElement, k *big.Int) GroupElement {
+	return new(bn256.G1).ScalarMult(a.(*bn256.G1), new(big.Int).Mod(k, bn256.Order))
+}
+
+func (g *BN256Group) Inv(a GroupElement) GroupElement {
+	return new(bn256.G1).Neg(a.(*bn256.G1))
+}
+
+func (g *BN256Group) Bytes(a GroupElement) []byte {
+	return a.(*bn256.G1).Marshal()
+}
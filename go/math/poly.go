@@ -0,0 +1,313 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/fft"
+)
+
+// FFTThreshold is the minimal number of coefficients of both polynomials
+// for which Poly.Mul switches from the schoolbook multiplication to FFT
+var FFTThreshold = 64
+
+var (
+	ErrDivisionByZero    = errors.New("division by zero polynomial")
+	ErrDuplicatePoints   = errors.New("interpolation points should be distinct")
+	ErrInvalidPointCount = errors.New("number of x and y values should be equal")
+)
+
+// Poly is a univariate polynomial over the prime field F_P.
+// Coefficients are stored from the lowest degree: Coeffs[i] is the coefficient of x^i.
+// Leading coefficient is never zero, so the zero polynomial has no coefficients.
+type Poly struct {
+	Coeffs []*big.Int
+	P      *big.Int
+}
+
+// NewPoly creates c_0 + c_1*x + ... + c_n*x^n over F_p
+func NewPoly(p *big.Int, coeffs ...*big.Int) *Poly {
+	res := &Poly{
+		Coeffs: make([]*big.Int, len(coeffs)),
+		P:      p,
+	}
+
+	for i, c := range coeffs {
+		res.Coeffs[i] = new(big.Int).Mod(c, p)
+	}
+
+	return res.trim()
+}
+
+// Degree returns the degree of polynomial or -1 for the zero polynomial
+func (a *Poly) Degree() int {
+	return len(a.Coeffs) - 1
+}
+
+func (a *Poly) IsZero() bool {
+	return len(a.Coeffs) == 0
+}
+
+// Coeff returns the coefficient of x^i
+func (a *Poly) Coeff(i int) *big.Int {
+	if i < 0 || i >= len(a.Coeffs) {
+		return big.NewInt(0)
+	}
+
+	return a.Coeffs[i]
+}
+
+func (a *Poly) Equal(b *Poly) bool {
+	if len(a.Coeffs) != len(b.Coeffs) {
+		return false
+	}
+
+	for i := range a.Coeffs {
+		if a.Coeffs[i].Cmp(b.Coeffs[i]) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a *Poly) Add(b *Poly) *Poly {
+	res := make([]*big.Int, max(len(a.Coeffs), len(b.Coeffs)))
+	for i := range res {
+		res[i] = add(a.Coeff(i), b.Coeff(i), a.P)
+	}
+
+	return (&Poly{Coeffs: res, P: a.P}).trim()
+}
+
+func (a *Poly) Sub(b *Poly) *Poly {
+	res := make([]*big.Int, max(len(a.Coeffs), len(b.Coeffs)))
+	for i := range res {
+		res[i] = sub(a.Coeff(i), b.Coeff(i), a.P)
+	}
+
+	return (&Poly{Coeffs: res, P: a.P}).trim()
+}
+
+// MulScalar returns k*a(x)
+func (a *Poly) MulScalar(k *big.Int) *Poly {
+	res := make([]*big.Int, len(a.Coeffs))
+	for i := range res {
+		res[i] = mul(a.Coeffs[i], k, a.P)
+	}
+
+	return (&Poly{Coeffs: res, P: a.P}).trim()
+}
+
+// Mul returns a(x)*b(x). FFT is used if both polynomials have at least FFTThreshold coefficients
+// and F_P contains the root of unity of the required power of two order.
+func (a *Poly) Mul(b *Poly) *Poly {
+	if a.IsZero() || b.IsZero() {
+		return &Poly{P: a.P}
+	}
+
+	if min(len(a.Coeffs), len(b.Coeffs)) >= FFTThreshold {
+		if res, ok := a.mulFFT(b); ok {
+			return res
+		}
+	}
+
+	res := make([]*big.Int, len(a.Coeffs)+len(b.Coeffs)-1)
+	for i := range res {
+		res[i] = big.NewInt(0)
+	}
+
+	for i, x := range a.Coeffs {
+		for j, y := range b.Coeffs {
+			res[i+j].Add(res[i+j], new(big.Int).Mul(x, y))
+		}
+	}
+
+	for i := range res {
+		res[i].Mod(res[i], a.P)
+	}
+
+	return (&Poly{Coeffs: res, P: a.P}).trim()
+}
+
+func (a *Poly) mulFFT(b *Poly) (*Poly, bool) {
+	size := 1
+	for size < len(a.Coeffs)+len(b.Coeffs)-1 {
+		size <<= 1
+	}
+
+	domain, ok := rootsOfUnity(size, a.P)
+	if !ok {
+		return nil, false
+	}
+
+	pad := func(c []*big.Int) []*big.Int {
+		res := make([]*big.Int, size)
+		copy(res, c)
+		for i := len(c); i < size; i++ {
+			res[i] = big.NewInt(0)
+		}
+
+		return res
+	}
+
+	av := fft.FFT(pad(a.Coeffs), domain, a.P)
+	bv := fft.FFT(pad(b.Coeffs), domain, a.P)
+	for i := range av {
+		av[i] = mul(av[i], bv[i], a.P)
+	}
+
+	return (&Poly{Coeffs: fft.FFTInverse(av, domain, a.P), P: a.P}).trim(), true
+}
+
+// DivMod returns q(x) and r(x) such that a(x) = q(x)*b(x) + r(x) and deg(r) < deg(b)
+func (a *Poly) DivMod(b *Poly) (q, r *Poly, err error) {
+	if b.IsZero() {
+		return nil, nil, ErrDivisionByZero
+	}
+
+	if a.Degree() < b.Degree() {
+		return &Poly{P: a.P}, NewPoly(a.P, a.Coeffs...), nil
+	}
+
+	rem := make([]*big.Int, len(a.Coeffs))
+	for i := range rem {
+		rem[i] = new(big.Int).Set(a.Coeffs[i])
+	}
+
+	quo := make([]*big.Int, a.Degree()-b.Degree()+1)
+	lead := new(big.Int).ModInverse(b.Coeffs[b.Degree()], a.P)
+
+	for i := len(quo) - 1; i >= 0; i-- {
+		c := mul(rem[i+b.Degree()], lead, a.P)
+		quo[i] = c
+
+		for j, y := range b.Coeffs {
+			rem[i+j] = sub(rem[i+j], mul(c, y, a.P), a.P)
+		}
+	}
+
+	return (&Poly{Coeffs: quo, P: a.P}).trim(), (&Poly{Coeffs: rem[:b.Degree()], P: a.P}).trim(), nil
+}
+
+// Eval evaluates the polynomial at x using Horner's method
+func (a *Poly) Eval(x *big.Int) *big.Int {
+	res := big.NewInt(0)
+	for i := len(a.Coeffs) - 1; i >= 0; i-- {
+		res = add(mul(res, x, a.P), a.Coeffs[i], a.P)
+	}
+
+	return res
+}
+
+// Derivative returns the formal derivative a'(x)
+func (a *Poly) Derivative() *Poly {
+	if len(a.Coeffs) <= 1 {
+		return &Poly{P: a.P}
+	}
+
+	res := make([]*big.Int, len(a.Coeffs)-1)
+	for i := range res {
+		res[i] = mul(a.Coeffs[i+1], big.NewInt(int64(i+1)), a.P)
+	}
+
+	return (&Poly{Coeffs: res, P: a.P}).trim()
+}
+
+// GCD returns the monic greatest common divisor of a(x) and b(x) using Euclidean algorithm
+func (a *Poly) GCD(b *Poly) *Poly {
+	x, y := a, b
+	for !y.IsZero() {
+		_, r, _ := x.DivMod(y)
+		x, y = y, r
+	}
+
+	if x.IsZero() {
+		return x
+	}
+
+	return x.MulScalar(new(big.Int).ModInverse(x.Coeffs[x.Degree()], x.P))
+}
+
+// Interpolate returns the polynomial of degree < len(xs) with a(x_i) = y_i using Lagrange interpolation
+// More information: https://en.wikipedia.org/wiki/Lagrange_polynomial
+func Interpolate(p *big.Int, xs, ys []*big.Int) (*Poly, error) {
+	if len(xs) != len(ys) {
+		return nil, ErrInvalidPointCount
+	}
+
+	// z(x) = prod(x - x_i)
+	z := Vanishing(p, xs...)
+	res := &Poly{P: p}
+
+	for i := range xs {
+		// l_i(x) = z(x) / (x - x_i) / prod(x_i - x_j)
+		li, _, err := z.DivMod(NewPoly(p, new(big.Int).Neg(xs[i]), big.NewInt(1)))
+		if err != nil {
+			return nil, err
+		}
+
+		den := li.Eval(xs[i])
+		if den.Sign() == 0 {
+			return nil, ErrDuplicatePoints
+		}
+
+		res = res.Add(li.MulScalar(div(ys[i], den, p)))
+	}
+
+	return res, nil
+}
+
+// Vanishing returns z(x) = (x - x_0)*(x - x_1)*...*(x - x_n)
+func Vanishing(p *big.Int, xs ...*big.Int) *Poly {
+	res := NewPoly(p, big.NewInt(1))
+	for _, x := range xs {
+		res = res.Mul(NewPoly(p, new(big.Int).Neg(x), big.NewInt(1)))
+	}
+
+	return res
+}
+
+func (a *Poly) trim() *Poly {
+	n := len(a.Coeffs)
+	for n > 0 && a.Coeffs[n-1].Sign() == 0 {
+		n--
+	}
+
+	a.Coeffs = a.Coeffs[:n]
+	return a
+}
+
+// rootsOfUnity returns [1, w, w^2, ..., w^(n-1)] where w is a primitive n-th root of unity in F_p, n is a power of two
+func rootsOfUnity(n int, p *big.Int) ([]*big.Int, bool) {
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	order := big.NewInt(int64(n))
+
+	if new(big.Int).Mod(pm1, order).Sign() != 0 {
+		return nil, false
+	}
+
+	exp := new(big.Int).Div(pm1, order)
+	for g := int64(2); g < 1000; g++ {
+		w := new(big.Int).Exp(big.NewInt(g), exp, p)
+
+		// w has order n if w^(n/2) = -1
+		if n > 1 && new(big.Int).Exp(w, big.NewInt(int64(n/2)), p).Cmp(pm1) != 0 {
+			continue
+		}
+
+		res := make([]*big.Int, n)
+		res[0] = big.NewInt(1)
+		for i := 1; i < n; i++ {
+			res[i] = mul(res[i-1], w, p)
+		}
+
+		return res, true
+	}
+
+	return nil, false
+}
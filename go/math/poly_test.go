@@ -0,0 +1,136 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/cloudflare/bn256"
+)
+
+// alt_bn128 scalar field has roots of unity of order 2^28
+var fftPrime, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+func randPoly(p *big.Int, n int) *Poly {
+	coeffs := make([]*big.Int, n)
+	for i := range coeffs {
+		var err error
+		if coeffs[i], err = rand.Int(rand.Reader, p); err != nil {
+			panic(err)
+		}
+	}
+
+	return NewPoly(p, coeffs...)
+}
+
+func TestPolyMul(t *testing.T) {
+	a := randPoly(fftPrime, 100)
+	b := randPoly(fftPrime, 150)
+
+	res, ok := a.mulFFT(b)
+	if !ok {
+		panic("FFT multiplication is not supported")
+	}
+
+	if !res.Equal(a.Mul(b)) {
+		panic("FFT multiplications are not equal")
+	}
+
+	threshold := FFTThreshold
+	FFTThreshold = 1 << 30
+	defer func() { FFTThreshold = threshold }()
+
+	if !res.Equal(a.Mul(b)) {
+		panic("FFT and schoolbook multiplications are not equal")
+	}
+
+	x := big.NewInt(12345)
+	if res.Eval(x).Cmp(mul(a.Eval(x), b.Eval(x), fftPrime)) != 0 {
+		panic("product evaluation is not equal")
+	}
+}
+
+func TestPolyDivMod(t *testing.T) {
+	a := randPoly(bn256.Order, 20)
+	b := randPoly(bn256.Order, 7)
+
+	q, r, err := a.DivMod(b)
+	if err != nil {
+		panic(err)
+	}
+
+	if r.Degree() >= b.Degree() || !q.Mul(b).Add(r).Equal(a) {
+		panic("a != q*b + r")
+	}
+
+	if _, _, err := a.DivMod(NewPoly(bn256.Order)); err != ErrDivisionByZero {
+		panic("division by zero was accepted")
+	}
+
+	// remainder of the lower degree dividend should not share memory with it
+	q, r, err = b.DivMod(a)
+	if err != nil {
+		panic(err)
+	}
+
+	if !q.IsZero() || !r.Equal(b) {
+		panic("invalid division by higher degree polynomial")
+	}
+
+	r.Coeffs[0].Add(r.Coeffs[0], big.NewInt(1))
+	r.Coeffs[1] = big.NewInt(0)
+	if r.Equal(b) {
+		panic("remainder shares memory with dividend")
+	}
+}
+
+func TestPolyGCD(t *testing.T) {
+	p := big.NewInt(337)
+
+	common := Vanishing(p, ints(1, 2, 3)...)
+	a := common.Mul(Vanishing(p, ints(4, 5)...))
+	b := common.Mul(Vanishing(p, ints(6)...)).MulScalar(big.NewInt(10))
+
+	if !a.GCD(b).Equal(common) {
+		panic("gcd is not equal")
+	}
+
+	// (x^3 + 2x)' = 3x^2 + 2
+	if !NewPoly(p, ints(0, 2, 0, 1)...).Derivative().Equal(NewPoly(p, ints(2, 0, 3)...)) {
+		panic("derivative is not equal")
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	a := randPoly(bn256.Order, 10)
+
+	xs := make([]*big.Int, 10)
+	ys := make([]*big.Int, 10)
+	for i := range xs {
+		xs[i] = big.NewInt(int64(i * 7))
+		ys[i] = a.Eval(xs[i])
+	}
+
+	res, err := Interpolate(bn256.Order, xs, ys)
+	if err != nil {
+		panic(err)
+	}
+
+	if !res.Equal(a) {
+		panic("interpolated polynomial is not equal")
+	}
+
+	for _, x := range xs {
+		if Vanishing(bn256.Order, xs...).Eval(x).Sign() != 0 {
+			panic("vanishing polynomial is not zero")
+		}
+	}
+
+	if _, err := Interpolate(bn256.Order, ints(1, 1), ints(2, 3)); err != ErrDuplicatePoints {
+		panic("duplicate points were accepted")
+	}
+}
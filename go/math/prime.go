@@ -0,0 +1,374 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// smallPrimeBound is the bound below which primality is proven by trial division
+const smallPrimeBound = 1 << 32
+
+var (
+	ErrInvalidPrimeSize   = errors.New("invalid prime size")
+	ErrInvalidCertificate = errors.New("invalid prime certificate")
+)
+
+var smallPrimes = []int64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71, 73, 79, 83, 89, 97}
+
+// BailliePSW implements Baillie-PSW primality test: Miller-Rabin test with base 2 and strong Lucas test.
+// There are no known composite numbers that pass this test.
+// More information: https://en.wikipedia.org/wiki/Baillie%E2%80%93PSW_primality_test
+func BailliePSW(n *big.Int) bool {
+	if n.Cmp(big.NewInt(2)) < 0 {
+		return false
+	}
+
+	if n.Bit(0) == 0 {
+		return n.Cmp(big.NewInt(2)) == 0
+	}
+
+	for _, p := range smallPrimes {
+		if n.Cmp(big.NewInt(p)) == 0 {
+			return true
+		}
+
+		if new(big.Int).Mod(n, big.NewInt(p)).Sign() == 0 {
+			return false
+		}
+	}
+
+	return MillerRabin(n, big.NewInt(2)) && StrongLucas(n)
+}
+
+// MillerRabin checks that odd n > 2 is a strong probable prime to the given base
+// More information: https://en.wikipedia.org/wiki/Miller%E2%80%93Rabin_primality_test
+func MillerRabin(n, base *big.Int) bool {
+	// n - 1 = d * 2^s
+	nm1 := new(big.Int).Sub(n, big.NewInt(1))
+	s := nm1.TrailingZeroBits()
+	d := new(big.Int).Rsh(nm1, s)
+
+	x := new(big.Int).Exp(base, d, n)
+	if x.Cmp(big.NewInt(1)) == 0 || x.Cmp(nm1) == 0 {
+		return true
+	}
+
+	for i := uint(1); i < s; i++ {
+		x = mul(x, x, n)
+		if x.Cmp(nm1) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StrongLucas checks that odd n > 2 is a strong Lucas probable prime with parameters chosen by Selfridge's method A:
+// D is the first of 5, -7, 9, -11, ... with Jacobi(D, n) = -1, P = 1 and Q = (1 - D)/4.
+// More information: https://en.wikipedia.org/wiki/Lucas_pseudoprime
+func StrongLucas(n *big.Int) bool {
+	// Jacobi(D, n) is never -1 for squares
+	if sqrt := new(big.Int).Sqrt(n); new(big.Int).Mul(sqrt, sqrt).Cmp(n) == 0 {
+		return false
+	}
+
+	D := big.NewInt(5)
+	for {
+		j, err := Jacobi(D, n)
+		if err != nil {
+			return false
+		}
+
+		if j.Sign() < 0 {
+			break
+		}
+
+		if j.Sign() == 0 && new(big.Int).Abs(D).Cmp(n) != 0 {
+			return false
+		}
+
+		if D.Sign() > 0 {
+			D.Neg(D.Add(D, big.NewInt(2)))
+		} else {
+			D.Neg(D.Sub(D, big.NewInt(2)))
+		}
+	}
+
+	Q := new(big.Int).Div(new(big.Int).Sub(big.NewInt(1), D), big.NewInt(4))
+	Q.Mod(Q, n)
+	Dn := new(big.Int).Mod(D, n)
+
+	// n + 1 = d * 2^s
+	np1 := new(big.Int).Add(n, big.NewInt(1))
+	s := np1.TrailingZeroBits()
+	d := new(big.Int).Rsh(np1, s)
+
+	half := func(x *big.Int) *big.Int {
+		if x.Bit(0) == 1 {
+			x = new(big.Int).Add(x, n)
+		}
+
+		return new(big.Int).Rsh(x, 1)
+	}
+
+	// U_1 = 1, V_1 = P = 1, Qk = Q^1
+	U, V, Qk := big.NewInt(1), big.NewInt(1), new(big.Int).Set(Q)
+	for i := d.BitLen() - 2; i >= 0; i-- {
+		// U_2k = U_k*V_k, V_2k = V_k^2 - 2Q^k
+		U = mul(U, V, n)
+		V = sub(mul(V, V, n), mul(big.NewInt(2), Qk, n), n)
+		Qk = mul(Qk, Qk, n)
+
+		if d.Bit(i) == 1 {
+			// U_k+1 = (P*U_k + V_k)/2, V_k+1 = (D*U_k + P*V_k)/2
+			U, V = half(add(U, V, n)), half(add(mul(Dn, U, n), V, n))
+			Qk = mul(Qk, Q, n)
+		}
+	}
+
+	if U.Sign() == 0 || V.Sign() == 0 {
+		return true
+	}
+
+	for r := uint(1); r < s; r++ {
+		V = sub(mul(V, V, n), mul(big.NewInt(2), Qk, n), n)
+		if V.Sign() == 0 {
+			return true
+		}
+
+		Qk = mul(Qk, Qk, n)
+	}
+
+	return false
+}
+
+// GenSafePrime generates the safe prime p = 2q + 1 of the given bit size where q is also prime
+func GenSafePrime(bits int) (p, q *big.Int, err error) {
+	if bits < 3 {
+		return nil, nil, ErrInvalidPrimeSize
+	}
+
+	for {
+		q, err = rand.Prime(rand.Reader, bits-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// q = 1 (mod 3) gives p = 0 (mod 3)
+		if bits > 3 && new(big.Int).Mod(q, big.NewInt(3)).Cmp(big.NewInt(1)) == 0 {
+			continue
+		}
+
+		p = new(big.Int).Add(new(big.Int).Lsh(q, 1), big.NewInt(1))
+		if BailliePSW(p) && BailliePSW(q) {
+			return p, q, nil
+		}
+	}
+}
+
+// GenStrongPrime generates the strong prime p of the given bit size using Gordon's algorithm:
+// p - 1 has a large prime factor r, p + 1 has a large prime factor s and r - 1 has a large prime factor t.
+// More information: https://en.wikipedia.org/wiki/Strong_prime#Strong_primes_in_cryptography
+func GenStrongPrime(bits int) (*big.Int, error) {
+	if bits < 64 {
+		return nil, ErrInvalidPrimeSize
+	}
+
+	// s and t have about half of p size, r has a little bit more than t
+	size := bits/2 - 8
+
+	for {
+		s, err := rand.Prime(rand.Reader, size)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := rand.Prime(rand.Reader, size-8)
+		if err != nil {
+			return nil, err
+		}
+
+		// r = 2it + 1
+		r := new(big.Int).Add(new(big.Int).Lsh(t, 1), big.NewInt(1))
+		for !BailliePSW(r) {
+			r.Add(r, new(big.Int).Lsh(t, 1))
+		}
+
+		// p0 = 2(s^(r-2) mod r)s - 1, so p0 = 1 (mod r) and p0 = -1 (mod s)
+		p0 := new(big.Int).Exp(s, new(big.Int).Sub(r, big.NewInt(2)), r)
+		p0.Mul(p0, s)
+		p0.Lsh(p0, 1)
+		p0.Sub(p0, big.NewInt(1))
+
+		// p = p0 + 2jrs with p of the given bit size
+		rs2 := new(big.Int).Lsh(new(big.Int).Mul(r, s), 1)
+		min := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+		if p0.Cmp(min) < 0 {
+			j := new(big.Int).Div(new(big.Int).Sub(min, p0), rs2)
+			p0.Add(p0, new(big.Int).Mul(j.Add(j, big.NewInt(1)), rs2))
+		}
+
+		for p := p0; p.BitLen() == bits; p.Add(p, rs2) {
+			if BailliePSW(p) {
+				return p, nil
+			}
+		}
+	}
+}
+
+// PrimeCertificate is the Pocklington certificate of primality of N.
+// Numbers below 2^32 are certified without factors using trial division.
+// More information: https://en.wikipedia.org/wiki/Pocklington_primality_test
+type PrimeCertificate struct {
+	N *big.Int
+	// Factors are the prime factors of N - 1 with product F > sqrt(N)
+	Factors []*PocklingtonFactor
+}
+
+// PocklingtonFactor is the prime factor Q^E of N - 1 with the witness A:
+// A^(N-1) = 1 (mod N) and gcd(A^((N-1)/Q) - 1, N) = 1
+type PocklingtonFactor struct {
+	Q           *big.Int
+	E           int
+	A           *big.Int
+	Certificate *PrimeCertificate
+}
+
+// ProvablePrime generates the prime of the given bit size with its primality certificate using Maurer's method:
+// n = 2Rq + 1, where q is a recursively generated provable prime with q > sqrt(n).
+// More information: https://link.springer.com/article/10.1007/BF00202269
+func ProvablePrime(bits int) (*big.Int, *PrimeCertificate, error) {
+	if bits < 2 {
+		return nil, nil, ErrInvalidPrimeSize
+	}
+
+	if bits <= 32 {
+		for {
+			p, err := rand.Prime(rand.Reader, bits)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if trialDivision(p) {
+				return p, &PrimeCertificate{N: p}, nil
+			}
+		}
+	}
+
+	// q has ceil(bits/2) + 1 bits, so q^2 >= 2^bits > n for both even and odd bits
+	q, qCert, err := ProvablePrime((bits+1)/2 + 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// R in [2^(bits-2)/q, 2^(bits-1)/q), so n = 2Rq + 1 has exactly the given bit size
+	low := new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), uint(bits-2)), q)
+	high := new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)), q)
+
+	for {
+		R, err := rand.Int(rand.Reader, new(big.Int).Sub(high, low))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		R.Add(R, low)
+		n := new(big.Int).Lsh(new(big.Int).Mul(R, q), 1)
+		n.Add(n, big.NewInt(1))
+
+		if n.BitLen() != bits || !BailliePSW(n) {
+			continue
+		}
+
+		for a := int64(2); a < 100; a++ {
+			factor := &PocklingtonFactor{Q: q, E: 1, A: big.NewInt(a), Certificate: qCert}
+			if checkPocklington(n, factor) {
+				return n, &PrimeCertificate{N: n, Factors: []*PocklingtonFactor{factor}}, nil
+			}
+		}
+	}
+}
+
+// VerifyPrimeCertificate verifies the primality certificate recursively
+func VerifyPrimeCertificate(cert *PrimeCertificate) error {
+	if cert == nil || cert.N == nil || cert.N.Cmp(big.NewInt(2)) < 0 {
+		return ErrInvalidCertificate
+	}
+
+	if len(cert.Factors) == 0 {
+		if cert.N.Cmp(big.NewInt(smallPrimeBound)) >= 0 || !trialDivision(cert.N) {
+			return ErrInvalidCertificate
+		}
+
+		return nil
+	}
+
+	nm1 := new(big.Int).Sub(cert.N, big.NewInt(1))
+	F := big.NewInt(1)
+
+	for _, f := range cert.Factors {
+		if f == nil || f.Q == nil || f.A == nil || f.E < 1 {
+			return ErrInvalidCertificate
+		}
+
+		if f.Certificate == nil || f.Certificate.N == nil || f.Certificate.N.Cmp(f.Q) != 0 {
+			return ErrInvalidCertificate
+		}
+
+		if err := VerifyPrimeCertificate(f.Certificate); err != nil {
+			return err
+		}
+
+		if !checkPocklington(cert.N, f) {
+			return ErrInvalidCertificate
+		}
+
+		F.Mul(F, new(big.Int).Exp(f.Q, big.NewInt(int64(f.E)), nil))
+	}
+
+	// F | N - 1 and F^2 > N
+	if new(big.Int).Mod(nm1, F).Sign() != 0 || new(big.Int).Mul(F, F).Cmp(cert.N) <= 0 {
+		return ErrInvalidCertificate
+	}
+
+	return nil
+}
+
+func checkPocklington(n *big.Int, f *PocklingtonFactor) bool {
+	nm1 := new(big.Int).Sub(n, big.NewInt(1))
+	if new(big.Int).Exp(f.A, nm1, n).Cmp(big.NewInt(1)) != 0 {
+		return false
+	}
+
+	t := new(big.Int).Exp(f.A, new(big.Int).Div(nm1, f.Q), n)
+	t.Sub(t, big.NewInt(1))
+	return new(big.Int).GCD(nil, nil, t, n).Cmp(big.NewInt(1)) == 0
+}
+
+// trialDivision checks the primality of n < 2^32 by division on all odd numbers up to sqrt(n)
+func trialDivision(n *big.Int) bool {
+	if !n.IsUint64() || n.Uint64() >= smallPrimeBound {
+		return false
+	}
+
+	v := n.Uint64()
+	if v < 2 {
+		return false
+	}
+
+	if v%2 == 0 {
+		return v == 2
+	}
+
+	for i := uint64(3); i*i <= v; i += 2 {
+		if v%i == 0 {
+			return false
+		}
+	}
+
+	return true
+}
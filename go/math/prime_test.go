@@ -0,0 +1,99 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestBailliePSW(t *testing.T) {
+	for i := int64(0); i < 20000; i++ {
+		n := big.NewInt(i)
+		if BailliePSW(n) != n.ProbablyPrime(20) {
+			panic(fmt.Sprintf("wrong result for %d", i))
+		}
+	}
+
+	// strong pseudoprimes to base 2
+	for _, n := range []int64{2047, 3277, 4033, 4681, 8321, 3215031751} {
+		if !MillerRabin(big.NewInt(n), big.NewInt(2)) || BailliePSW(big.NewInt(n)) {
+			panic(fmt.Sprintf("wrong result for %d", n))
+		}
+	}
+
+	// strong Lucas pseudoprimes
+	for _, n := range []int64{5459, 5777, 10877, 16109, 18971} {
+		if !StrongLucas(big.NewInt(n)) || BailliePSW(big.NewInt(n)) {
+			panic(fmt.Sprintf("wrong result for %d", n))
+		}
+	}
+
+	// 2^127 - 1
+	mersenne := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	if !BailliePSW(mersenne) {
+		panic("prime check failed")
+	}
+}
+
+func TestGenSafePrime(t *testing.T) {
+	p, q, err := GenSafePrime(256)
+	if err != nil {
+		panic(err)
+	}
+
+	if p.BitLen() != 256 || !p.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+		panic("invalid safe prime")
+	}
+
+	if new(big.Int).Add(new(big.Int).Lsh(q, 1), big.NewInt(1)).Cmp(p) != 0 {
+		panic("p != 2q + 1")
+	}
+}
+
+func TestGenStrongPrime(t *testing.T) {
+	p, err := GenStrongPrime(512)
+	if err != nil {
+		panic(err)
+	}
+
+	if p.BitLen() != 512 || !p.ProbablyPrime(20) {
+		panic("invalid strong prime")
+	}
+}
+
+func TestProvablePrime(t *testing.T) {
+	for _, bits := range []int{67, 255, 521} {
+		p, cert, err := ProvablePrime(bits)
+		if err != nil {
+			panic(err)
+		}
+
+		if p.BitLen() != bits || !p.ProbablyPrime(20) {
+			panic("invalid provable prime")
+		}
+
+		if err := VerifyPrimeCertificate(cert); err != nil {
+			panic(err)
+		}
+	}
+
+	p, cert, err := ProvablePrime(512)
+	if err != nil {
+		panic(err)
+	}
+
+	cert.N = new(big.Int).Add(p, big.NewInt(2))
+	if err := VerifyPrimeCertificate(cert); err != ErrInvalidCertificate {
+		panic("invalid certificate was accepted")
+	}
+
+	cert.N = p
+	cert.Factors[0].Certificate.N = nil
+	if err := VerifyPrimeCertificate(cert); err != ErrInvalidCertificate {
+		panic("certificate with nil nested value was accepted")
+	}
+}
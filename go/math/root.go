@@ -0,0 +1,56 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidRoot = errors.New("root degree should be positive and value non-negative")
+
+// Root returns floor(x^(1/k)) for x >= 0 and k >= 1 using Newton's method
+func Root(x *big.Int, k int) (*big.Int, error) {
+	if k < 1 || x.Sign() < 0 {
+		return nil, ErrInvalidRoot
+	}
+
+	if k == 1 || x.Cmp(big.NewInt(2)) < 0 {
+		return new(big.Int).Set(x), nil
+	}
+
+	if k == 2 {
+		return new(big.Int).Sqrt(x), nil
+	}
+
+	bigK := big.NewInt(int64(k))
+	bigK1 := big.NewInt(int64(k - 1))
+
+	// initial value 2^ceil(bits/k) is greater than the root
+	y := new(big.Int).Lsh(big.NewInt(1), uint((x.BitLen()+k-1)/k))
+
+	for {
+		// z = ((k - 1)*y + x / y^(k-1)) / k
+		z := new(big.Int).Div(x, new(big.Int).Exp(y, bigK1, nil))
+		z.Add(z, new(big.Int).Mul(bigK1, y))
+		z.Div(z, bigK)
+
+		if z.Cmp(y) >= 0 {
+			return y, nil
+		}
+
+		y = z
+	}
+}
+
+// IsSquare returns true if x is a perfect square
+func IsSquare(x *big.Int) bool {
+	if x.Sign() < 0 {
+		return false
+	}
+
+	r := new(big.Int).Sqrt(x)
+	return r.Mul(r, r).Cmp(x) == 0
+}
@@ -0,0 +1,71 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestRoot(t *testing.T) {
+	for k := 1; k <= 7; k++ {
+		for i := 0; i < 20; i++ {
+			x, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 300))
+			r, err := Root(x, k)
+			if err != nil {
+				panic(err)
+			}
+
+			// r^k <= x < (r+1)^k
+			if new(big.Int).Exp(r, big.NewInt(int64(k)), nil).Cmp(x) > 0 ||
+				new(big.Int).Exp(new(big.Int).Add(r, big.NewInt(1)), big.NewInt(int64(k)), nil).Cmp(x) <= 0 {
+				panic("invalid root")
+			}
+		}
+	}
+
+	r1, _ := Root(big.NewInt(1000), 3)
+	r2, _ := Root(big.NewInt(999), 3)
+	if r1.Cmp(big.NewInt(10)) != 0 || r2.Cmp(big.NewInt(9)) != 0 {
+		panic("invalid cube root")
+	}
+
+	if _, err := Root(big.NewInt(-8), 3); err != ErrInvalidRoot {
+		panic("root of negative value was accepted")
+	}
+
+	if _, err := Root(big.NewInt(8), 0); err != ErrInvalidRoot {
+		panic("root of zero degree was accepted")
+	}
+
+	if !IsSquare(big.NewInt(144)) || IsSquare(big.NewInt(143)) {
+		panic("invalid square check")
+	}
+}
+
+func TestFermatFactor(t *testing.T) {
+	p, _ := rand.Prime(rand.Reader, 256)
+	q := new(big.Int).Add(p, big.NewInt(2))
+	for !q.ProbablyPrime(20) {
+		q.Add(q, big.NewInt(2))
+	}
+
+	n := new(big.Int).Mul(p, q)
+
+	a, b, err := FermatFactor(n, 10)
+	if err != nil {
+		panic(err)
+	}
+
+	if a.Cmp(q) != 0 || b.Cmp(p) != 0 {
+		panic("invalid factors")
+	}
+
+	r, _ := rand.Prime(rand.Reader, 128)
+	if _, _, err := FermatFactor(new(big.Int).Mul(p, r), 10); err != ErrFactorNotFound {
+		panic("factors of distant primes found")
+	}
+}
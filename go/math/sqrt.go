@@ -0,0 +1,234 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+var ErrNotQuadraticResidue = errors.New("value is not a quadratic residue")
+
+// SqrtModPrime solves x^2 = n (mod p) for any prime p.
+// Uses the direct formulas for p = 3 (mod 4) and p = 5 (mod 8) and Tonelli-Shanks algorithm otherwise.
+func SqrtModPrime(n, p *big.Int) (*big.Int, error) {
+	n = new(big.Int).Mod(n, p)
+
+	if p.Cmp(big.NewInt(2)) == 0 || n.Sign() == 0 {
+		return n, nil
+	}
+
+	if new(big.Int).Exp(n, new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(1)), 1), p).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrNotQuadraticResidue
+	}
+
+	switch {
+	case new(big.Int).Mod(p, big.NewInt(4)).Cmp(big.NewInt(3)) == 0:
+		// x = n^((p+1)/4)
+		return new(big.Int).Exp(n, new(big.Int).Rsh(new(big.Int).Add(p, big.NewInt(1)), 2), p), nil
+	case new(big.Int).Mod(p, big.NewInt(8)).Cmp(big.NewInt(5)) == 0:
+		// Atkin: v = (2n)^((p-5)/8), i = 2nv^2, x = nv(i - 1)
+		n2 := mul(n, big.NewInt(2), p)
+		v := new(big.Int).Exp(n2, new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(5)), 3), p)
+		i := mul(n2, mul(v, v, p), p)
+		return mul(mul(n, v, p), sub(i, big.NewInt(1), p), p), nil
+	default:
+		return TonelliShanks(n, p)
+	}
+}
+
+// TonelliShanks solves x^2 = n (mod p) for odd prime p and quadratic residue n
+// More information: https://en.wikipedia.org/wiki/Tonelli%E2%80%93Shanks_algorithm
+func TonelliShanks(n, p *big.Int) (*big.Int, error) {
+	n = new(big.Int).Mod(n, p)
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+
+	if new(big.Int).Exp(n, new(big.Int).Rsh(pm1, 1), p).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrNotQuadraticResidue
+	}
+
+	// p - 1 = q * 2^s
+	s := pm1.TrailingZeroBits()
+	q := new(big.Int).Rsh(pm1, s)
+
+	// z is a quadratic non-residue
+	z := big.NewInt(2)
+	for new(big.Int).Exp(z, new(big.Int).Rsh(pm1, 1), p).Cmp(pm1) != 0 {
+		z.Add(z, big.NewInt(1))
+	}
+
+	m := s
+	c := new(big.Int).Exp(z, q, p)
+	t := new(big.Int).Exp(n, q, p)
+	r := new(big.Int).Exp(n, new(big.Int).Rsh(new(big.Int).Add(q, big.NewInt(1)), 1), p)
+
+	for t.Cmp(big.NewInt(1)) != 0 {
+		// the least i such that t^(2^i) = 1
+		i := uint(0)
+		for tt := new(big.Int).Set(t); tt.Cmp(big.NewInt(1)) != 0; tt = mul(tt, tt, p) {
+			i++
+		}
+
+		b := new(big.Int).Exp(c, new(big.Int).Lsh(big.NewInt(1), m-i-1), p)
+		m = i
+		c = mul(b, b, p)
+		t = mul(t, c, p)
+		r = mul(r, b, p)
+	}
+
+	return r, nil
+}
+
+// SqrtModPrimePower solves x^2 = n (mod p^k) using Hensel lifting of the root modulo p
+// More information: https://en.wikipedia.org/wiki/Hensel%27s_lemma
+func SqrtModPrimePower(n, p *big.Int, k int) (*big.Int, error) {
+	pk := new(big.Int).Exp(p, big.NewInt(int64(k)), nil)
+	n = new(big.Int).Mod(n, pk)
+
+	if n.Sign() == 0 {
+		return n, nil
+	}
+
+	// n = p^v * m, gcd(m, p) = 1
+	v := 0
+	m := new(big.Int).Set(n)
+	for new(big.Int).Mod(m, p).Sign() == 0 {
+		m.Div(m, p)
+		v++
+	}
+
+	if v%2 == 1 {
+		return nil, ErrNotQuadraticResidue
+	}
+
+	// x = p^(v/2) * sqrt(m) (mod p^(k-v))
+	x, err := sqrtCoprimePrimePower(m, p, k-v)
+	if err != nil {
+		return nil, err
+	}
+
+	x.Mul(x, new(big.Int).Exp(p, big.NewInt(int64(v/2)), nil))
+	return x.Mod(x, pk), nil
+}
+
+func sqrtCoprimePrimePower(n, p *big.Int, k int) (*big.Int, error) {
+	if p.Cmp(big.NewInt(2)) == 0 {
+		return sqrtModPowerOfTwo(n, k)
+	}
+
+	x, err := SqrtModPrime(n, p)
+	if err != nil {
+		return nil, err
+	}
+
+	// x_{i+1} = x_i - (x_i^2 - n) / (2x_i) (mod p^(i+1))
+	mod := new(big.Int).Set(p)
+	for i := 1; i < k; i++ {
+		mod.Mul(mod, p)
+		f := sub(mul(x, x, mod), n, mod)
+		x = sub(x, div(f, mul(big.NewInt(2), x, mod), mod), mod)
+	}
+
+	return x, nil
+}
+
+// sqrtModPowerOfTwo solves x^2 = n (mod 2^k) for odd n
+func sqrtModPowerOfTwo(n *big.Int, k int) (*big.Int, error) {
+	switch {
+	case k == 1:
+		return big.NewInt(1), nil
+	case k == 2:
+		if new(big.Int).Mod(n, big.NewInt(4)).Cmp(big.NewInt(1)) != 0 {
+			return nil, ErrNotQuadraticResidue
+		}
+
+		return big.NewInt(1), nil
+	}
+
+	if new(big.Int).Mod(n, big.NewInt(8)).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrNotQuadraticResidue
+	}
+
+	// x^2 = n (mod 2^i) => x or x + 2^(i-1) is a root modulo 2^(i+1)
+	x := big.NewInt(1)
+	for i := 3; i < k; i++ {
+		mod := new(big.Int).Lsh(big.NewInt(1), uint(i+1))
+		if mul(x, x, mod).Cmp(new(big.Int).Mod(n, mod)) != 0 {
+			x.Add(x, new(big.Int).Lsh(big.NewInt(1), uint(i-1)))
+		}
+	}
+
+	return x, nil
+}
+
+// SqrtMod solves x^2 = n (mod m) where m is given by its factorization.
+// Roots modulo every prime power are combined with CRT. Returns all roots in increasing order
+// if n is coprime to m, otherwise only the roots built from one root modulo every prime power and its negation.
+func SqrtMod(n *big.Int, factors []*PrimePower) ([]*big.Int, error) {
+	moduli := make([]*big.Int, 0, len(factors))
+	roots := make([][]*big.Int, 0, len(factors))
+
+	for _, f := range factors {
+		pk := new(big.Int).Exp(f.P, big.NewInt(int64(f.E)), nil)
+
+		x, err := SqrtModPrimePower(n, f.P, f.E)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := []*big.Int{x, sub(big.NewInt(0), x, pk)}
+		if f.P.Cmp(big.NewInt(2)) == 0 && f.E >= 3 {
+			// x + 2^(k-1) and -x + 2^(k-1) are also roots modulo 2^k
+			half := new(big.Int).Lsh(big.NewInt(1), uint(f.E-1))
+			candidates = append(candidates, add(x, half, pk), sub(half, x, pk))
+		}
+
+		moduli = append(moduli, pk)
+		roots = append(roots, candidates)
+	}
+
+	res := make(map[string]*big.Int)
+	combination := make([]*big.Int, len(roots))
+
+	var combine func(i int) error
+	combine = func(i int) error {
+		if i == len(roots) {
+			x, _, err := CRT(combination, moduli)
+			if err != nil {
+				return err
+			}
+
+			res[x.String()] = x
+			return nil
+		}
+
+		for _, r := range roots[i] {
+			combination[i] = r
+			if err := combine(i + 1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := combine(0); err != nil {
+		return nil, err
+	}
+
+	list := make([]*big.Int, 0, len(res))
+	for _, x := range res {
+		list = append(list, x)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Cmp(list[j]) < 0 })
+	return list, nil
+}
+
+// SqrtModPQ returns all four square roots of n modulo p*q for distinct odd primes p and q
+func SqrtModPQ(n, p, q *big.Int) ([]*big.Int, error) {
+	return SqrtMod(n, []*PrimePower{{P: p, E: 1}, {P: q, E: 1}})
+}
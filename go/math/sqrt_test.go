@@ -0,0 +1,125 @@
+// Package math
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package math
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestSqrtModPrime(t *testing.T) {
+	// covers p = 2, p = 3 (mod 4), p = 5 (mod 8) and p = 1 (mod 8)
+	for _, p := range ints(2, 3, 5, 7, 13, 17, 29, 41, 73, 97, 113, 257, 65537) {
+		squares := make(map[int64]bool)
+		for x := int64(0); x < p.Int64(); x++ {
+			squares[x*x%p.Int64()] = true
+		}
+
+		for n := int64(0); n < p.Int64(); n++ {
+			x, err := SqrtModPrime(big.NewInt(n), p)
+			if !squares[n] {
+				if err != ErrNotQuadraticResidue {
+					panic(fmt.Sprintf("non-residue %d mod %d was accepted", n, p))
+				}
+
+				continue
+			}
+
+			if err != nil || mul(x, x, p).Int64() != n {
+				panic(fmt.Sprintf("wrong root of %d mod %d", n, p))
+			}
+		}
+	}
+
+	// p - 1 is divisible by 2^32
+	p, _ := new(big.Int).SetString("0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001", 0)
+	a, err := rand.Int(rand.Reader, p)
+	if err != nil {
+		panic(err)
+	}
+
+	n := mul(a, a, p)
+	x, err := SqrtModPrime(n, p)
+	if err != nil {
+		panic(err)
+	}
+
+	if mul(x, x, p).Cmp(n) != 0 {
+		panic("wrong root")
+	}
+}
+
+func TestSqrtModPrimePower(t *testing.T) {
+	for _, test := range []struct {
+		p int64
+		k int
+	}{{3, 5}, {5, 3}, {2, 10}, {7, 2}} {
+		p := big.NewInt(test.p)
+		pk := new(big.Int).Exp(p, big.NewInt(int64(test.k)), nil)
+
+		squares := make(map[int64]bool)
+		for x := int64(0); x < pk.Int64(); x++ {
+			squares[x*x%pk.Int64()] = true
+		}
+
+		for n := int64(0); n < pk.Int64(); n++ {
+			x, err := SqrtModPrimePower(big.NewInt(n), p, test.k)
+			if !squares[n] {
+				if err == nil {
+					panic(fmt.Sprintf("non-residue %d mod %d was accepted", n, pk))
+				}
+
+				continue
+			}
+
+			if err != nil || mul(x, x, pk).Int64() != n {
+				panic(fmt.Sprintf("wrong root of %d mod %d", n, pk))
+			}
+		}
+	}
+}
+
+func TestSqrtModPQ(t *testing.T) {
+	p, err := rand.Prime(rand.Reader, 128)
+	if err != nil {
+		panic(err)
+	}
+
+	q, err := rand.Prime(rand.Reader, 128)
+	if err != nil {
+		panic(err)
+	}
+
+	n := new(big.Int).Mul(p, q)
+
+	a, err := rand.Int(rand.Reader, n)
+	if err != nil {
+		panic(err)
+	}
+
+	roots, err := SqrtModPQ(mul(a, a, n), p, q)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(roots) != 4 {
+		panic("expected four roots")
+	}
+
+	found := false
+	for _, x := range roots {
+		if mul(x, x, n).Cmp(mul(a, a, n)) != 0 {
+			panic("wrong root")
+		}
+
+		found = found || x.Cmp(a) == 0
+	}
+
+	if !found {
+		panic("original root is missing")
+	}
+}
@@ -0,0 +1,125 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `attacks.go` implements classic attacks on textbook RSA for teaching and audits:
+// Wiener's attack on small private exponent, common modulus attack, Håstad's broadcast attack
+// and Fermat factorization of the modulus with close primes.
+// Attacks accept arbitrary public exponent because they are not limited to keys of this package.
+package rsa
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/math"
+)
+
+var ErrAttackFailed = errors.New("attack failed")
+
+// WienerAttack recovers private exponent d and primes p, q from public key (n, e)
+// if d < n^(1/4)/3 using continued fraction expansion of e/n.
+// More information: https://en.wikipedia.org/wiki/Wiener%27s_attack
+func WienerAttack(n, e *big.Int) (d, p, q *big.Int, err error) {
+	cf, err := math.ContinuedFraction(e, n)
+	if err != nil {
+		return nil, nil, nil, ErrAttackFailed
+	}
+
+	h, k := math.Convergents(cf)
+
+	for i := range h {
+		// candidate k/d for e*d - k*phi(n) = 1
+		kk, dd := h[i], k[i]
+		if kk.Sign() == 0 {
+			continue
+		}
+
+		ed1 := new(big.Int).Sub(new(big.Int).Mul(e, dd), big.NewInt(1))
+		phi, r := new(big.Int).QuoRem(ed1, kk, new(big.Int))
+		if r.Sign() != 0 {
+			continue
+		}
+
+		if p, q, ok := factorFromPhi(n, phi); ok {
+			return dd, p, q, nil
+		}
+	}
+
+	return nil, nil, nil, ErrAttackFailed
+}
+
+// factorFromPhi solves x^2 - (n - phi + 1)x + n = 0 which roots are p and q
+func factorFromPhi(n, phi *big.Int) (p, q *big.Int, ok bool) {
+	s := new(big.Int).Add(new(big.Int).Sub(n, phi), big.NewInt(1))
+
+	// discriminant = s^2 - 4n
+	disc := new(big.Int).Sub(new(big.Int).Mul(s, s), new(big.Int).Lsh(n, 2))
+	if !math.IsSquare(disc) {
+		return nil, nil, false
+	}
+
+	t := new(big.Int).Sqrt(disc)
+	p = new(big.Int).Rsh(new(big.Int).Add(s, t), 1)
+	q = new(big.Int).Rsh(new(big.Int).Sub(s, t), 1)
+
+	if q.Cmp(big.NewInt(1)) <= 0 || new(big.Int).Mul(p, q).Cmp(n) != 0 {
+		return nil, nil, false
+	}
+
+	return p, q, true
+}
+
+// CommonModulusAttack recovers the message m from c1 = m^e1 mod n and c2 = m^e2 mod n if gcd(e1, e2) = 1:
+// m = c1^a * c2^b mod n, where a*e1 + b*e2 = 1
+func CommonModulusAttack(n, e1, e2, c1, c2 *big.Int) (*big.Int, error) {
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, e1, e2).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrAttackFailed
+	}
+
+	// negative exponent is handled by Exp with the inverse of the base
+	x := new(big.Int).Exp(c1, a, n)
+	y := new(big.Int).Exp(c2, b, n)
+	if x == nil || y == nil {
+		return nil, ErrAttackFailed
+	}
+
+	return x.Mul(x, y).Mod(x, n), nil
+}
+
+// HastadAttack recovers the message m sent to e recipients with the same public exponent e:
+// c_i = m^e mod n_i, so m^e < n_1*...*n_e can be found with CRT and the e-th root is taken over integers.
+// More information: https://en.wikipedia.org/wiki/Coppersmith%27s_attack#H%C3%A5stad%27s_broadcast_attack
+func HastadAttack(e int, n, c []*big.Int) (*big.Int, error) {
+	if e < 2 || len(n) < e || len(n) != len(c) {
+		return nil, ErrAttackFailed
+	}
+
+	x, _, err := math.CRT(c[:e], n[:e])
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := math.Root(x, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if new(big.Int).Exp(m, big.NewInt(int64(e)), nil).Cmp(x) != 0 {
+		return nil, ErrAttackFailed
+	}
+
+	return m, nil
+}
+
+// FermatAttack factors the modulus with close primes using Fermat factorization
+func FermatAttack(n *big.Int, steps int) (p, q *big.Int, err error) {
+	p, q, err = math.FermatFactor(n, steps)
+	if err != nil {
+		return nil, nil, ErrAttackFailed
+	}
+
+	return p, q, nil
+}
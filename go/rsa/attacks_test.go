@@ -0,0 +1,107 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func randPrime(bits int) *big.Int {
+	p, err := rand.Prime(rand.Reader, bits)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func TestWienerAttack(t *testing.T) {
+	p, q := randPrime(512), randPrime(512)
+	n := new(big.Int).Mul(p, q)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, big.NewInt(1)), new(big.Int).Sub(q, big.NewInt(1)))
+
+	// d < n^(1/4)/3
+	var d, pubE *big.Int
+	for pubE == nil {
+		d = randPrime(200)
+		pubE = new(big.Int).ModInverse(d, phi)
+	}
+
+	res, p1, q1, err := WienerAttack(n, pubE)
+	if err != nil {
+		panic(err)
+	}
+
+	if res.Cmp(d) != 0 || new(big.Int).Mul(p1, q1).Cmp(n) != 0 {
+		panic("invalid recovered key")
+	}
+
+	if _, _, _, err := WienerAttack(n, e); err != ErrAttackFailed {
+		panic("attack should fail for large d")
+	}
+}
+
+func TestCommonModulusAttack(t *testing.T) {
+	n := new(big.Int).Mul(randPrime(512), randPrime(512))
+	e1, e2 := big.NewInt(Exp), big.NewInt(17)
+
+	msg, _ := rand.Int(rand.Reader, n)
+	c1 := new(big.Int).Exp(msg, e1, n)
+	c2 := new(big.Int).Exp(msg, e2, n)
+
+	res, err := CommonModulusAttack(n, e1, e2, c1, c2)
+	if err != nil {
+		panic(err)
+	}
+
+	if res.Cmp(msg) != 0 {
+		panic("invalid recovered message")
+	}
+}
+
+func TestHastadAttack(t *testing.T) {
+	const pubE = 3
+
+	msg := new(big.Int).SetBytes([]byte("Hello World"))
+
+	var n, c []*big.Int
+	for i := 0; i < pubE; i++ {
+		ni := new(big.Int).Mul(randPrime(512), randPrime(512))
+		n = append(n, ni)
+		c = append(c, new(big.Int).Exp(msg, big.NewInt(pubE), ni))
+	}
+
+	res, err := HastadAttack(pubE, n, c)
+	if err != nil {
+		panic(err)
+	}
+
+	if res.Cmp(msg) != 0 {
+		panic("invalid recovered message")
+	}
+
+	if _, err := HastadAttack(pubE, n[:2], c[:2]); err != ErrAttackFailed {
+		panic("attack should fail for less than e cyphers")
+	}
+}
+
+func TestFermatAttack(t *testing.T) {
+	p := randPrime(1024)
+	q := new(big.Int).Add(p, new(big.Int).Lsh(big.NewInt(1), 400))
+	for !q.ProbablyPrime(20) {
+		q.Add(q, big.NewInt(2))
+	}
+
+	p1, q1, err := FermatAttack(new(big.Int).Mul(p, q), 10)
+	if err != nil {
+		panic(err)
+	}
+
+	if p1.Cmp(q) != 0 || q1.Cmp(p) != 0 {
+		panic("invalid factors")
+	}
+}
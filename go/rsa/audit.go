@@ -0,0 +1,175 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `audit.go` implements the weak key auditor that runs the attacks from `attacks.go`
+// and simple sanity checks against the set of public keys.
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Weakness is the kind of problem found by Auditor
+type Weakness string
+
+const (
+	// WeaknessSmallModulus is reported for modulus shorter than Auditor.MinBits
+	WeaknessSmallModulus Weakness = "small-modulus"
+	// WeaknessSmallFactor is reported if the modulus has small prime divisor
+	WeaknessSmallFactor Weakness = "small-factor"
+	// WeaknessClosePrimes is reported if the modulus was factored with Fermat method
+	WeaknessClosePrimes Weakness = "close-primes"
+	// WeaknessSharedModulus is reported for keys with the same modulus
+	WeaknessSharedModulus Weakness = "shared-modulus"
+	// WeaknessSharedFactor is reported for keys which moduli have a common prime factor
+	WeaknessSharedFactor Weakness = "shared-factor"
+	// WeaknessBroadcast is reported if there are at least e keys, so the same message
+	// encrypted to all of them can be recovered with Håstad's attack
+	WeaknessBroadcast Weakness = "broadcast"
+)
+
+const (
+	defaultMinBits     = 2048
+	defaultFermatSteps = 1 << 16
+	smallFactorBound   = 1 << 16
+)
+
+// Finding describes the weakness of the key. P and Q are set if the modulus was factored.
+type Finding struct {
+	Key      *PublicKey
+	Weakness Weakness
+	Details  string
+	P, Q     *big.Int
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Weakness, f.Details)
+}
+
+// Auditor checks public keys against known attacks.
+// Wiener's attack is not run: keys of this package have e = 65537, and e*d = 1 + k*phi(n)
+// gives d > phi(n)/e, which is far above the n^(1/4)/3 bound of the attack.
+type Auditor struct {
+	// MinBits is the minimal accepted modulus size
+	MinBits int
+	// FermatSteps is the number of Fermat factorization iterations
+	FermatSteps int
+}
+
+// NewAuditor returns Auditor with 2048 bits minimal modulus and 2^16 Fermat steps
+func NewAuditor() *Auditor {
+	return &Auditor{
+		MinBits:     defaultMinBits,
+		FermatSteps: defaultFermatSteps,
+	}
+}
+
+// Audit runs all checks for each key and for each pair of keys. Returns nil if nothing was found.
+func (a *Auditor) Audit(keys ...*PublicKey) []*Finding {
+	var res []*Finding
+
+	for _, pk := range keys {
+		res = append(res, a.auditKey(pk)...)
+	}
+
+	for i := range keys {
+		for j := i + 1; j < len(keys); j++ {
+			res = append(res, a.auditPair(keys[i], keys[j])...)
+		}
+	}
+
+	// HastadAttack needs e encryptions of the same message under distinct moduli,
+	// so m^e < n_1*...*n_e and the e-th root can be taken over integers
+	if moduli := distinctModuli(keys); moduli >= Exp {
+		res = append(res, &Finding{
+			Weakness: WeaknessBroadcast,
+			Details:  fmt.Sprintf("%d distinct moduli with the same public exponent %d", moduli, Exp),
+		})
+	}
+
+	return res
+}
+
+func (a *Auditor) auditKey(pk *PublicKey) []*Finding {
+	var res []*Finding
+
+	if pk.n.BitLen() < a.MinBits {
+		res = append(res, &Finding{
+			Key:      pk,
+			Weakness: WeaknessSmallModulus,
+			Details:  fmt.Sprintf("modulus has %d bits, at least %d expected", pk.n.BitLen(), a.MinBits),
+		})
+	}
+
+	for i := int64(2); i < smallFactorBound; i++ {
+		p := big.NewInt(i)
+		if p.Cmp(pk.n) < 0 && new(big.Int).Mod(pk.n, p).Sign() == 0 {
+			res = append(res, &Finding{
+				Key:      pk,
+				Weakness: WeaknessSmallFactor,
+				Details:  fmt.Sprintf("modulus is divisible by %d", i),
+				P:        new(big.Int).Div(pk.n, p),
+				Q:        p,
+			})
+
+			// other attacks will find the same factor
+			return res
+		}
+	}
+
+	if p, q, err := FermatAttack(pk.n, a.FermatSteps); err == nil {
+		res = append(res, &Finding{
+			Key:      pk,
+			Weakness: WeaknessClosePrimes,
+			Details:  "modulus was factored with Fermat method",
+			P:        p,
+			Q:        q,
+		})
+	}
+
+	return res
+}
+
+func (a *Auditor) auditPair(x, y *PublicKey) []*Finding {
+	if x.n.Cmp(y.n) == 0 {
+		return []*Finding{{
+			Key:      y,
+			Weakness: WeaknessSharedModulus,
+			Details:  "modulus is shared with another key",
+		}}
+	}
+
+	g := new(big.Int).GCD(nil, nil, x.n, y.n)
+	if g.Cmp(big.NewInt(1)) == 0 {
+		return nil
+	}
+
+	return []*Finding{
+		{
+			Key:      x,
+			Weakness: WeaknessSharedFactor,
+			Details:  "modulus has a common factor with another key",
+			P:        new(big.Int).Div(x.n, g),
+			Q:        g,
+		},
+		{
+			Key:      y,
+			Weakness: WeaknessSharedFactor,
+			Details:  "modulus has a common factor with another key",
+			P:        new(big.Int).Div(y.n, g),
+			Q:        new(big.Int).Set(g),
+		},
+	}
+}
+
+func distinctModuli(keys []*PublicKey) int {
+	seen := make(map[string]struct{}, len(keys))
+	for _, pk := range keys {
+		seen[pk.n.String()] = struct{}{}
+	}
+
+	return len(seen)
+}
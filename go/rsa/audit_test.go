@@ -0,0 +1,70 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsa
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAuditor(t *testing.T) {
+	auditor := NewAuditor()
+
+	p, q, r := randPrime(1024), randPrime(1024), randPrime(1024)
+	good := NewPublicKey(new(big.Int).Mul(p, q))
+
+	if res := auditor.Audit(good); len(res) != 0 {
+		panic("unexpected finding: " + res[0].String())
+	}
+
+	near := new(big.Int).Add(p, big.NewInt(2))
+	for !near.ProbablyPrime(20) {
+		near.Add(near, big.NewInt(2))
+	}
+
+	tests := []struct {
+		keys     []*PublicKey
+		weakness Weakness
+	}{
+		{
+			keys:     []*PublicKey{NewPublicKey(new(big.Int).Mul(randPrime(512), randPrime(512)))},
+			weakness: WeaknessSmallModulus,
+		},
+		{
+			keys:     []*PublicKey{NewPublicKey(new(big.Int).Mul(p, big.NewInt(65521)))},
+			weakness: WeaknessSmallFactor,
+		},
+		{
+			keys:     []*PublicKey{NewPublicKey(new(big.Int).Mul(p, near))},
+			weakness: WeaknessClosePrimes,
+		},
+		{
+			keys:     []*PublicKey{good, NewPublicKey(good.N())},
+			weakness: WeaknessSharedModulus,
+		},
+		{
+			keys:     []*PublicKey{good, NewPublicKey(new(big.Int).Mul(p, r))},
+			weakness: WeaknessSharedFactor,
+		},
+	}
+
+	for _, test := range tests {
+		found := false
+		for _, f := range auditor.Audit(test.keys...) {
+			if f.Weakness != test.weakness {
+				continue
+			}
+
+			found = true
+			if f.P != nil && new(big.Int).Mul(f.P, f.Q).Cmp(f.Key.n) != 0 {
+				panic("invalid factors")
+			}
+		}
+
+		if !found {
+			panic("weakness not found: " + string(test.weakness))
+		}
+	}
+}
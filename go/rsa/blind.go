@@ -0,0 +1,177 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `blind.go` implements Chaum RSA blind signatures following RFC 9474 (RSABSSA)
+// (https://www.rfc-editor.org/rfc/rfc9474) with EMSA-PSS encoding and SHA-384.
+//
+// Flow: the client calls Prepare and Blind, the signer calls BlindSign on the blinded message,
+// the client calls Finalize to unblind the signature. Anyone can check it with Verify on the prepared message.
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+)
+
+// BlindVariant is one of RFC 9474 RSABSSA variants
+type BlindVariant struct {
+	Name       string
+	SaltLength int
+	Randomized bool
+}
+
+var (
+	// BlindSHA384PSSRandomized is RSABSSA-SHA384-PSS-Randomized, the default variant
+	BlindSHA384PSSRandomized = &BlindVariant{Name: "RSABSSA-SHA384-PSS-Randomized", SaltLength: sha512.Size384, Randomized: true}
+	// BlindSHA384PSSZeroRandomized is RSABSSA-SHA384-PSSZERO-Randomized
+	BlindSHA384PSSZeroRandomized = &BlindVariant{Name: "RSABSSA-SHA384-PSSZERO-Randomized", SaltLength: 0, Randomized: true}
+	// BlindSHA384PSSDeterministic is RSABSSA-SHA384-PSS-Deterministic
+	BlindSHA384PSSDeterministic = &BlindVariant{Name: "RSABSSA-SHA384-PSS-Deterministic", SaltLength: sha512.Size384}
+	// BlindSHA384PSSZeroDeterministic is RSABSSA-SHA384-PSSZERO-Deterministic
+	BlindSHA384PSSZeroDeterministic = &BlindVariant{Name: "RSABSSA-SHA384-PSSZERO-Deterministic", SaltLength: 0}
+)
+
+// msgPrefixSize is the size of random message prefix for randomized variants
+const msgPrefixSize = 32
+
+var (
+	ErrInvalidMessage     = errors.New("message is not coprime with the modulus")
+	ErrInvalidBlind       = errors.New("blinded message is invalid")
+	ErrInvalidBlindSigner = errors.New("signing failure")
+)
+
+// Prepare adds random prefix to the message with the default variant BlindSHA384PSSRandomized
+func Prepare(msg []byte) ([]byte, error) {
+	return BlindSHA384PSSRandomized.Prepare(msg)
+}
+
+// Blind blinds the prepared message with the default variant BlindSHA384PSSRandomized.
+// Returns the blinded message and the inverse of blind.
+func Blind(input []byte, pk *PublicKey) (blinded, inv []byte, err error) {
+	return BlindSHA384PSSRandomized.Blind(input, pk)
+}
+
+// Finalize unblinds the signature with the default variant BlindSHA384PSSRandomized
+func Finalize(input, blindSig, inv []byte, pk *PublicKey) ([]byte, error) {
+	return BlindSHA384PSSRandomized.Finalize(input, blindSig, inv, pk)
+}
+
+// Verify verifies the signature with the default variant BlindSHA384PSSRandomized
+func Verify(input, sig []byte, pk *PublicKey) error {
+	return BlindSHA384PSSRandomized.Verify(input, sig, pk)
+}
+
+// BlindSign signs the blinded message: s = m^d mod n. The result is verified before return.
+func BlindSign(blinded []byte, prv *PrivateKey) ([]byte, error) {
+	if len(blinded) != prv.Size() {
+		return nil, ErrInvalidBlind
+	}
+
+	m := new(big.Int).SetBytes(blinded)
+	if m.Cmp(prv.n) >= 0 {
+		return nil, ErrInvalidBlind
+	}
+
+	s := Decrypt(m, prv)
+	if Encrypt(s, prv.PublicKey).Cmp(m) != 0 {
+		return nil, ErrInvalidBlindSigner
+	}
+
+	return s.FillBytes(make([]byte, prv.Size())), nil
+}
+
+// Prepare returns the message to sign: msg_prefix || msg with 32 random bytes prefix for randomized variants
+func (v *BlindVariant) Prepare(msg []byte) ([]byte, error) {
+	if !v.Randomized {
+		return msg, nil
+	}
+
+	prefix := make([]byte, msgPrefixSize, msgPrefixSize+len(msg))
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, err
+	}
+
+	return append(prefix, msg...), nil
+}
+
+// Blind blinds the prepared message. Returns the blinded message and the inverse of blind.
+func (v *BlindVariant) Blind(input []byte, pk *PublicKey) (blinded, inv []byte, err error) {
+	salt := make([]byte, v.SaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, nil, err
+	}
+
+	r, _, err := blindingFactor(pk.n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blind(input, salt, r, pk)
+}
+
+// Finalize unblinds the signature and verifies it
+func (v *BlindVariant) Finalize(input, blindSig, inv []byte, pk *PublicKey) ([]byte, error) {
+	if len(blindSig) != pk.Size() || len(inv) != pk.Size() {
+		return nil, ErrVerification
+	}
+
+	// s = z * inv mod n
+	s := new(big.Int).Mul(new(big.Int).SetBytes(blindSig), new(big.Int).SetBytes(inv))
+	s.Mod(s, pk.n)
+
+	sig := s.FillBytes(make([]byte, pk.Size()))
+	if err := v.Verify(input, sig, pk); err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+// Verify verifies RSASSA-PSS signature with SHA-384 and the variant salt length
+func (v *BlindVariant) Verify(input, sig []byte, pk *PublicKey) error {
+	m, err := verify(sig, pk)
+	if err != nil {
+		return err
+	}
+
+	return pssVerify(sha512.New384, hashSHA384(input), m, pk.n.BitLen()-1, v.SaltLength)
+}
+
+// blind returns z = m * r^e mod n and inv = r^-1 mod n, where m is EMSA-PSS encoding of the input
+func blind(input, salt []byte, r *big.Int, pk *PublicKey) (blinded, inv []byte, err error) {
+	em, err := emsaPSSEncode(sha512.New384, hashSHA384(input), pk.n.BitLen()-1, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := new(big.Int).SetBytes(em)
+	if new(big.Int).GCD(nil, nil, m, pk.n).Cmp(big.NewInt(1)) != 0 {
+		return nil, nil, ErrInvalidMessage
+	}
+
+	rInv := new(big.Int).ModInverse(r, pk.n)
+	if rInv == nil {
+		return nil, nil, ErrInvalidBlind
+	}
+
+	z := new(big.Int).Mul(m, Encrypt(r, pk))
+	z.Mod(z, pk.n)
+
+	return z.FillBytes(make([]byte, pk.Size())), rInv.FillBytes(make([]byte, pk.Size())), nil
+}
+
+func hashSHA384(data []byte) []byte {
+	return sumHash(sha512.New384, data)
+}
+
+func sumHash(newHash func() hash.Hash, data []byte) []byte {
+	h := newHash()
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,152 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsa
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+)
+
+type blindVector struct {
+	Name       string `json:"name"`
+	P          string `json:"p"`
+	Q          string `json:"q"`
+	N          string `json:"n"`
+	D          string `json:"d"`
+	Msg        string `json:"msg"`
+	MsgPrefix  string `json:"msg_prefix"`
+	InputMsg   string `json:"input_msg"`
+	Salt       string `json:"salt"`
+	Inv        string `json:"inv"`
+	BlindedMsg string `json:"blinded_msg"`
+	BlindSig   string `json:"blind_sig"`
+	Sig        string `json:"sig"`
+}
+
+func fromHex(s string) []byte {
+	res, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func fromHexInt(s string) *big.Int {
+	return new(big.Int).SetBytes(fromHex(s))
+}
+
+func TestBlindRFC9474(t *testing.T) {
+	data, err := os.ReadFile("testdata/rfc9474.json")
+	if err != nil {
+		panic(err)
+	}
+
+	var vectors []blindVector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		panic(err)
+	}
+
+	variants := map[string]*BlindVariant{
+		BlindSHA384PSSRandomized.Name:        BlindSHA384PSSRandomized,
+		BlindSHA384PSSZeroRandomized.Name:    BlindSHA384PSSZeroRandomized,
+		BlindSHA384PSSDeterministic.Name:     BlindSHA384PSSDeterministic,
+		BlindSHA384PSSZeroDeterministic.Name: BlindSHA384PSSZeroDeterministic,
+	}
+
+	for _, v := range vectors {
+		variant, ok := variants[v.Name]
+		if !ok {
+			panic("unknown variant " + v.Name)
+		}
+
+		key, err := newPrivateKey(NewPublicKey(fromHexInt(v.N)), fromHexInt(v.D), []*big.Int{fromHexInt(v.P), fromHexInt(v.Q)})
+		if err != nil {
+			panic(err)
+		}
+
+		input := append(fromHex(v.MsgPrefix), fromHex(v.Msg)...)
+		if !bytes.Equal(input, fromHex(v.InputMsg)) {
+			panic("invalid input message in " + v.Name)
+		}
+
+		r := new(big.Int).ModInverse(fromHexInt(v.Inv), key.n)
+		blinded, inv, err := blind(input, fromHex(v.Salt), r, key.PublicKey)
+		if err != nil {
+			panic(err)
+		}
+
+		if !bytes.Equal(blinded, fromHex(v.BlindedMsg)) || !bytes.Equal(inv, fromHex(v.Inv)) {
+			panic("invalid blinded message in " + v.Name)
+		}
+
+		blindSig, err := BlindSign(blinded, key)
+		if err != nil {
+			panic(err)
+		}
+
+		if !bytes.Equal(blindSig, fromHex(v.BlindSig)) {
+			panic("invalid blind signature in " + v.Name)
+		}
+
+		sig, err := variant.Finalize(input, blindSig, inv, key.PublicKey)
+		if err != nil {
+			panic(err)
+		}
+
+		if !bytes.Equal(sig, fromHex(v.Sig)) {
+			panic("invalid signature in " + v.Name)
+		}
+	}
+}
+
+func TestBlindSign(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	msg := []byte("blind message")
+
+	input, err := Prepare(msg)
+	if err != nil {
+		panic(err)
+	}
+
+	blinded, inv, err := Blind(input, key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	if !bytes.HasSuffix(input, msg) || len(input) != len(msg)+msgPrefixSize {
+		panic("invalid prepared message")
+	}
+
+	blindSig, err := BlindSign(blinded, key)
+	if err != nil {
+		panic(err)
+	}
+
+	sig, err := Finalize(input, blindSig, inv, key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := Verify(input, sig, key.PublicKey); err != nil {
+		panic(err)
+	}
+
+	if err := Verify(msg, sig, key.PublicKey); err == nil {
+		panic("signature verified without prefix")
+	}
+
+	if err := BlindSHA384PSSZeroRandomized.Verify(input, sig, key.PublicKey); err == nil {
+		panic("signature verified with invalid salt length")
+	}
+}
@@ -0,0 +1,103 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `crt.go` implements RSA decryption with Chinese Remainder Theorem (RFC 8017 section 5.1.2).
+// Cypher is blinded with random r^e against timing attacks and the result is verified
+// with the public exponent against Bellcore fault attacks.
+package rsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrFaultDetected = errors.New("fault detected in CRT computation")
+	ErrNoCRTValues   = errors.New("private key has no CRT values")
+)
+
+// Precompute calculates CRT values of the private key
+func (prv *PrivateKey) Precompute() {
+	prv.DP = new(big.Int).Mod(prv.D, new(big.Int).Sub(prv.P, big.NewInt(1)))
+	prv.DQ = new(big.Int).Mod(prv.D, new(big.Int).Sub(prv.Q, big.NewInt(1)))
+	prv.QInv = new(big.Int).ModInverse(prv.Q, prv.P)
+
+	R := new(big.Int).Mul(prv.P, prv.Q)
+	for _, r := range prv.Extra {
+		r.D = new(big.Int).Mod(prv.D, new(big.Int).Sub(r.R, big.NewInt(1)))
+		r.T = new(big.Int).ModInverse(R, r.R)
+		R.Mul(R, r.R)
+	}
+}
+
+// DecryptCRT decrypts the cypher using CRT with blinding and verifies the result
+func DecryptCRT(cypher *big.Int, prv *PrivateKey) (*big.Int, error) {
+	if prv.DP == nil || prv.DQ == nil || prv.QInv == nil {
+		return nil, ErrNoCRTValues
+	}
+
+	// blinded cypher c' = c*r^e, so m' = m*r
+	r, rInv, err := blindingFactor(prv.n)
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(big.Int).Mod(new(big.Int).Mul(cypher, Encrypt(r, prv.PublicKey)), prv.n)
+
+	m := new(big.Int).Mod(new(big.Int).Mul(crt(c, prv), rInv), prv.n)
+
+	// m^e = c (mod n) is broken if any of CRT exponentiations was faulty
+	if Encrypt(m, prv.PublicKey).Cmp(new(big.Int).Mod(cypher, prv.n)) != 0 {
+		return nil, ErrFaultDetected
+	}
+
+	return m, nil
+}
+
+func crt(c *big.Int, prv *PrivateKey) *big.Int {
+	// m_1 = c^dP mod p, m_2 = c^dQ mod q
+	m1 := new(big.Int).Exp(c, prv.DP, prv.P)
+	m2 := new(big.Int).Exp(c, prv.DQ, prv.Q)
+
+	// h = (m_1 - m_2)*qInv mod p, m = m_2 + q*h
+	h := new(big.Int).Sub(m1, m2)
+	h.Mul(h, prv.QInv)
+	h.Mod(h, prv.P)
+
+	m := new(big.Int).Add(m2, new(big.Int).Mul(prv.Q, h))
+
+	// h = (m_i - m)*t_i mod r_i, m = m + R*h
+	R := new(big.Int).Mul(prv.P, prv.Q)
+	for _, r := range prv.Extra {
+		mi := new(big.Int).Exp(c, r.D, r.R)
+		h = new(big.Int).Sub(mi, m)
+		h.Mul(h, r.T)
+		h.Mod(h, r.R)
+
+		m.Add(m, new(big.Int).Mul(R, h))
+		R.Mul(R, r.R)
+	}
+
+	return m
+}
+
+// blindingFactor returns random r coprime with n and r^-1 mod n
+func blindingFactor(n *big.Int) (r, rInv *big.Int, err error) {
+	for {
+		r, err = rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if r.Sign() == 0 {
+			continue
+		}
+
+		if rInv = new(big.Int).ModInverse(r, n); rInv != nil {
+			return r, rInv, nil
+		}
+	}
+}
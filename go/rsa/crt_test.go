@@ -0,0 +1,57 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestDecryptCRT(t *testing.T) {
+	for _, primes := range []int{2, 3, 4} {
+		key, err := GenerateMultiPrimeKey(primes)
+		if err != nil {
+			panic(err)
+		}
+
+		msg, err := rand.Int(rand.Reader, key.n)
+		if err != nil {
+			panic(err)
+		}
+
+		cypher := Encrypt(msg, key.PublicKey)
+
+		res, err := DecryptCRT(cypher, key)
+		if err != nil {
+			panic(err)
+		}
+
+		if res.Cmp(msg) != 0 || new(big.Int).Exp(cypher, key.D, key.n).Cmp(msg) != 0 {
+			panic("decrypted message is not equal")
+		}
+	}
+}
+
+func TestDecryptCRTFault(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	msg := new(big.Int).SetBytes([]byte("Hello World"))
+	cypher := Encrypt(msg, key.PublicKey)
+
+	// simulate the fault in the exponentiation modulo p
+	key.DP = new(big.Int).Add(key.DP, big.NewInt(1))
+
+	if _, err := DecryptCRT(cypher, key); err != ErrFaultDetected {
+		panic("fault was not detected")
+	}
+
+	if Decrypt(cypher, key).Cmp(msg) != 0 {
+		panic("decrypted message is not equal")
+	}
+}
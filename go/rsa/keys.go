@@ -0,0 +1,380 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `keys.go` implements keys conversion to and from crypto/rsa keys,
+// PEM encoded PKCS#1 and PKCS#8 formats and JWK (RFC 7517, RFC 7518 section 6.3).
+// Only keys with the public exponent Exp are supported.
+package rsa
+
+import (
+	stdrsa "crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/math"
+)
+
+const (
+	pemPKCS1PrivateKey = "RSA PRIVATE KEY"
+	pemPKCS1PublicKey  = "RSA PUBLIC KEY"
+	pemPKCS8PrivateKey = "PRIVATE KEY"
+)
+
+var (
+	ErrUnsupportedExponent = errors.New("only public exponent 65537 is supported")
+	ErrInvalidKey          = errors.New("invalid rsa key")
+	ErrInvalidPEM          = errors.New("invalid pem block")
+)
+
+// NewPublicKey creates the public key with modulus n
+func NewPublicKey(n *big.Int) *PublicKey {
+	return &PublicKey{n: new(big.Int).Set(n)}
+}
+
+// N returns the public modulus
+func (pk *PublicKey) N() *big.Int {
+	return new(big.Int).Set(pk.n)
+}
+
+// Validate checks that n is the product of distinct primes and e*d = 1 (mod lambda(n))
+func (prv *PrivateKey) Validate() error {
+	if prv.PublicKey == nil || prv.n == nil || prv.P == nil || prv.Q == nil || prv.D == nil {
+		return ErrInvalidKey
+	}
+
+	for _, r := range prv.Extra {
+		if r == nil || r.R == nil {
+			return ErrInvalidKey
+		}
+	}
+
+	n := big.NewInt(1)
+	seen := make(map[string]struct{})
+	orders := make([]*big.Int, 0, 2+len(prv.Extra))
+
+	for _, p := range prv.primes() {
+		if _, ok := seen[p.String()]; ok || !math.BailliePSW(p) {
+			return ErrInvalidKey
+		}
+
+		seen[p.String()] = struct{}{}
+		n.Mul(n, p)
+		orders = append(orders, new(big.Int).Sub(p, big.NewInt(1)))
+	}
+
+	if n.Cmp(prv.n) != 0 {
+		return ErrInvalidKey
+	}
+
+	// lambda(n) = lcm(p_1 - 1, ..., p_k - 1)
+	lambda := math.LCM(orders...)
+	if new(big.Int).Mod(new(big.Int).Mul(e, prv.D), lambda).Cmp(big.NewInt(1)) != 0 {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
+
+func (prv *PrivateKey) primes() []*big.Int {
+	res := []*big.Int{prv.P, prv.Q}
+	for _, r := range prv.Extra {
+		res = append(res, r.R)
+	}
+
+	return res
+}
+
+// ToStd converts the public key to crypto/rsa public key
+func (pk *PublicKey) ToStd() *stdrsa.PublicKey {
+	return &stdrsa.PublicKey{N: new(big.Int).Set(pk.n), E: Exp}
+}
+
+// ToStd converts the private key to crypto/rsa private key
+func (prv *PrivateKey) ToStd() *stdrsa.PrivateKey {
+	res := &stdrsa.PrivateKey{
+		PublicKey: *prv.PublicKey.ToStd(),
+		D:         new(big.Int).Set(prv.D),
+	}
+
+	for _, p := range prv.primes() {
+		res.Primes = append(res.Primes, new(big.Int).Set(p))
+	}
+
+	res.Precompute()
+	return res
+}
+
+// FromStdPublicKey converts crypto/rsa public key
+func FromStdPublicKey(pk *stdrsa.PublicKey) (*PublicKey, error) {
+	if pk.E != Exp {
+		return nil, ErrUnsupportedExponent
+	}
+
+	if pk.N == nil || pk.N.Sign() <= 0 {
+		return nil, ErrInvalidKey
+	}
+
+	return NewPublicKey(pk.N), nil
+}
+
+// FromStdPrivateKey converts and validates crypto/rsa private key
+func FromStdPrivateKey(prv *stdrsa.PrivateKey) (*PrivateKey, error) {
+	pub, err := FromStdPublicKey(&prv.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prv.Primes) < 2 || prv.D == nil {
+		return nil, ErrInvalidKey
+	}
+
+	return newPrivateKey(pub, prv.D, prv.Primes)
+}
+
+func newPrivateKey(pub *PublicKey, d *big.Int, primes []*big.Int) (*PrivateKey, error) {
+	key := &PrivateKey{
+		PublicKey: pub,
+		P:         new(big.Int).Set(primes[0]),
+		Q:         new(big.Int).Set(primes[1]),
+		D:         new(big.Int).Set(d),
+	}
+
+	for _, r := range primes[2:] {
+		key.Extra = append(key.Extra, &CRTPrime{R: new(big.Int).Set(r)})
+	}
+
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+
+	key.Precompute()
+	return key, nil
+}
+
+// MarshalPKCS1PrivateKeyPEM encodes the private key into "RSA PRIVATE KEY" PEM block
+func (prv *PrivateKey) MarshalPKCS1PrivateKeyPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemPKCS1PrivateKey, Bytes: x509.MarshalPKCS1PrivateKey(prv.ToStd())})
+}
+
+// ParsePKCS1PrivateKeyPEM decodes and validates the private key from "RSA PRIVATE KEY" PEM block
+func ParsePKCS1PrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	block, err := decodePEM(data, pemPKCS1PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromStdPrivateKey(key)
+}
+
+// MarshalPKCS1PublicKeyPEM encodes the public key into "RSA PUBLIC KEY" PEM block
+func (pk *PublicKey) MarshalPKCS1PublicKeyPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemPKCS1PublicKey, Bytes: x509.MarshalPKCS1PublicKey(pk.ToStd())})
+}
+
+// ParsePKCS1PublicKeyPEM decodes the public key from "RSA PUBLIC KEY" PEM block
+func ParsePKCS1PublicKeyPEM(data []byte) (*PublicKey, error) {
+	block, err := decodePEM(data, pemPKCS1PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS1PublicKey(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromStdPublicKey(key)
+}
+
+// MarshalPKCS8PrivateKeyPEM encodes the private key into "PRIVATE KEY" PEM block
+func (prv *PrivateKey) MarshalPKCS8PrivateKeyPEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(prv.ToStd())
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemPKCS8PrivateKey, Bytes: der}), nil
+}
+
+// ParsePKCS8PrivateKeyPEM decodes and validates the RSA private key from "PRIVATE KEY" PEM block
+func ParsePKCS8PrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	block, err := decodePEM(data, pemPKCS8PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*stdrsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	return FromStdPrivateKey(rsaKey)
+}
+
+func decodePEM(data []byte, blockType string) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, ErrInvalidPEM
+	}
+
+	return block.Bytes, nil
+}
+
+// JWK is the JSON Web Key representation of RSA key, private fields are empty for the public key
+type JWK struct {
+	Kty string      `json:"kty"`
+	N   string      `json:"n"`
+	E   string      `json:"e"`
+	D   string      `json:"d,omitempty"`
+	P   string      `json:"p,omitempty"`
+	Q   string      `json:"q,omitempty"`
+	DP  string      `json:"dp,omitempty"`
+	DQ  string      `json:"dq,omitempty"`
+	QI  string      `json:"qi,omitempty"`
+	Oth []*JWKPrime `json:"oth,omitempty"`
+}
+
+// JWKPrime is the additional prime of multi-prime key
+type JWKPrime struct {
+	R string `json:"r"`
+	D string `json:"d"`
+	T string `json:"t"`
+}
+
+// MarshalJWK encodes the public key into JWK JSON
+func (pk *PublicKey) MarshalJWK() ([]byte, error) {
+	return json.Marshal(pk.jwk())
+}
+
+// MarshalJWK encodes the private key into JWK JSON
+func (prv *PrivateKey) MarshalJWK() ([]byte, error) {
+	// CRT values are computed on the copy, so marshalling does not modify the key
+	key := &PrivateKey{PublicKey: prv.PublicKey, P: prv.P, Q: prv.Q, D: prv.D}
+	for _, r := range prv.Extra {
+		key.Extra = append(key.Extra, &CRTPrime{R: r.R})
+	}
+
+	key.Precompute()
+
+	jwk := key.PublicKey.jwk()
+	jwk.D = encodeJWKInt(key.D)
+	jwk.P = encodeJWKInt(key.P)
+	jwk.Q = encodeJWKInt(key.Q)
+	jwk.DP = encodeJWKInt(key.DP)
+	jwk.DQ = encodeJWKInt(key.DQ)
+	jwk.QI = encodeJWKInt(key.QInv)
+
+	for _, r := range key.Extra {
+		jwk.Oth = append(jwk.Oth, &JWKPrime{R: encodeJWKInt(r.R), D: encodeJWKInt(r.D), T: encodeJWKInt(r.T)})
+	}
+
+	return json.Marshal(jwk)
+}
+
+// ParsePublicJWK decodes the public key from JWK JSON
+func ParsePublicJWK(data []byte) (*PublicKey, error) {
+	jwk := &JWK{}
+	if err := json.Unmarshal(data, jwk); err != nil {
+		return nil, err
+	}
+
+	return jwk.publicKey()
+}
+
+// ParsePrivateJWK decodes and validates the private key from JWK JSON.
+// CRT values are recomputed from the primes.
+func ParsePrivateJWK(data []byte) (*PrivateKey, error) {
+	jwk := &JWK{}
+	if err := json.Unmarshal(data, jwk); err != nil {
+		return nil, err
+	}
+
+	pub, err := jwk.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	values := []string{jwk.D, jwk.P, jwk.Q}
+	for _, r := range jwk.Oth {
+		if r == nil {
+			return nil, ErrInvalidKey
+		}
+
+		values = append(values, r.R)
+	}
+
+	ints := make([]*big.Int, 0, len(values))
+	for _, v := range values {
+		x, err := decodeJWKInt(v)
+		if err != nil {
+			return nil, err
+		}
+
+		ints = append(ints, x)
+	}
+
+	return newPrivateKey(pub, ints[0], ints[1:])
+}
+
+func (pk *PublicKey) jwk() *JWK {
+	return &JWK{
+		Kty: "RSA",
+		N:   encodeJWKInt(pk.n),
+		E:   encodeJWKInt(e),
+	}
+}
+
+func (jwk *JWK) publicKey() (*PublicKey, error) {
+	if jwk.Kty != "RSA" {
+		return nil, ErrInvalidKey
+	}
+
+	n, err := decodeJWKInt(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	exp, err := decodeJWKInt(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if exp.Cmp(e) != 0 {
+		return nil, ErrUnsupportedExponent
+	}
+
+	return NewPublicKey(n), nil
+}
+
+func encodeJWKInt(x *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(x.Bytes())
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, ErrInvalidKey
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,154 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsa
+
+import (
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestKeyEncoding(t *testing.T) {
+	key, err := GenerateMultiPrimeKey(3)
+	if err != nil {
+		panic(err)
+	}
+
+	check := func(res *PrivateKey) {
+		if res.n.Cmp(key.n) != 0 || res.D.Cmp(key.D) != 0 || res.P.Cmp(key.P) != 0 || res.Q.Cmp(key.Q) != 0 {
+			panic("decoded key is not equal")
+		}
+
+		if len(res.Extra) != 1 || res.Extra[0].R.Cmp(key.Extra[0].R) != 0 || res.Extra[0].T.Cmp(key.Extra[0].T) != 0 {
+			panic("decoded extra primes are not equal")
+		}
+	}
+
+	res, err := ParsePKCS1PrivateKeyPEM(key.MarshalPKCS1PrivateKeyPEM())
+	if err != nil {
+		panic(err)
+	}
+
+	check(res)
+
+	data, err := key.MarshalPKCS8PrivateKeyPEM()
+	if err != nil {
+		panic(err)
+	}
+
+	if res, err = ParsePKCS8PrivateKeyPEM(data); err != nil {
+		panic(err)
+	}
+
+	check(res)
+
+	if data, err = key.MarshalJWK(); err != nil {
+		panic(err)
+	}
+
+	if res, err = ParsePrivateJWK(data); err != nil {
+		panic(err)
+	}
+
+	check(res)
+
+	pub, err := ParsePKCS1PublicKeyPEM(key.PublicKey.MarshalPKCS1PublicKeyPEM())
+	if err != nil {
+		panic(err)
+	}
+
+	if pub.n.Cmp(key.n) != 0 {
+		panic("decoded public key is not equal")
+	}
+
+	if data, err = key.PublicKey.MarshalJWK(); err != nil {
+		panic(err)
+	}
+
+	if pub, err = ParsePublicJWK(data); err != nil {
+		panic(err)
+	}
+
+	if pub.n.Cmp(key.n) != 0 {
+		panic("decoded public key is not equal")
+	}
+}
+
+func TestPrivateJWK(t *testing.T) {
+	key, err := GenerateMultiPrimeKey(3)
+	if err != nil {
+		panic(err)
+	}
+
+	key.DP, key.DQ, key.QInv = nil, nil, nil
+	key.Extra[0].D, key.Extra[0].T = nil, nil
+
+	data, err := key.MarshalJWK()
+	if err != nil {
+		panic(err)
+	}
+
+	if key.DP != nil || key.DQ != nil || key.QInv != nil || key.Extra[0].D != nil || key.Extra[0].T != nil {
+		panic("marshalling modified the key")
+	}
+
+	res, err := ParsePrivateJWK(data)
+	if err != nil {
+		panic(err)
+	}
+
+	if res.D.Cmp(key.D) != 0 || res.DP == nil || len(res.Extra) != 1 {
+		panic("decoded key is not equal")
+	}
+
+	jwk := &JWK{}
+	if err := json.Unmarshal(data, jwk); err != nil {
+		panic(err)
+	}
+
+	jwk.Oth = append(jwk.Oth, nil)
+	if data, err = json.Marshal(jwk); err != nil {
+		panic(err)
+	}
+
+	if _, err := ParsePrivateJWK(data); err != ErrInvalidKey {
+		panic("key with nil additional prime was accepted")
+	}
+}
+
+func TestFromStdPrivateKey(t *testing.T) {
+	std, err := stdrsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	key, err := FromStdPrivateKey(std)
+	if err != nil {
+		panic(err)
+	}
+
+	msg := new(big.Int).SetBytes([]byte("Hello World"))
+	if Decrypt(Encrypt(msg, key.PublicKey), key).Cmp(msg) != 0 {
+		panic("decrypted message is not equal")
+	}
+
+	std.D = new(big.Int).Add(std.D, big.NewInt(2))
+	if _, err := FromStdPrivateKey(std); err != ErrInvalidKey {
+		panic("invalid private exponent was accepted")
+	}
+
+	std.D.Sub(std.D, big.NewInt(2))
+	std.Primes[0] = new(big.Int).Add(std.Primes[0], big.NewInt(2))
+	if _, err := FromStdPrivateKey(std); err != ErrInvalidKey {
+		panic("invalid prime was accepted")
+	}
+
+	std.E = 3
+	if _, err := FromStdPublicKey(&std.PublicKey); err != ErrUnsupportedExponent {
+		panic("unsupported exponent was accepted")
+	}
+}
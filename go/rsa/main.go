@@ -5,6 +5,7 @@
 package rsa
 
 import (
+	"errors"
 	"math/big"
 
 	"github.com/olegfomenko/crypto/go/math"
@@ -16,6 +17,8 @@ const Exp = 65537
 
 var e = big.NewInt(Exp)
 
+var ErrInvalidPrimesCount = errors.New("invalid primes count")
+
 type PublicKey struct {
 	n *big.Int
 }
@@ -24,41 +27,87 @@ type PrivateKey struct {
 	*PublicKey
 	P, Q *big.Int
 	D    *big.Int
+
+	// CRT values: DP = D mod (P - 1), DQ = D mod (Q - 1), QInv = Q^-1 mod P
+	DP, DQ, QInv *big.Int
+	// Extra contains the additional primes of multi-prime key
+	Extra []*CRTPrime
+}
+
+// CRTPrime is the additional prime R of multi-prime key (RFC 8017 section 3.2) with
+// D = d mod (R - 1) and T = (P*Q*R_3*...*R_{i-1})^-1 mod R
+type CRTPrime struct {
+	R, D, T *big.Int
 }
 
 func GeneratePrivateKey() (*PrivateKey, error) {
-	p, err := math.GenRandPrime(Size)
-	if err != nil {
-		return nil, err
-	}
+	return GenerateMultiPrimeKey(2)
+}
 
-	q, err := math.GenRandPrime(Size)
-	if err != nil {
-		return nil, err
+// GenerateMultiPrimeKey generates the key with n = p_1*...*p_k of the same size as GeneratePrivateKey has
+func GenerateMultiPrimeKey(primes int) (*PrivateKey, error) {
+	if primes < 2 || 2*Size/primes < 8 {
+		return nil, ErrInvalidPrimesCount
 	}
 
-	n := new(big.Int).Mul(p, q)
+	list := make([]*big.Int, 0, primes)
+	seen := make(map[string]struct{}, primes)
+
+	for len(list) < primes {
+		p, err := math.GenRandPrime(2 * Size / primes)
+		if err != nil {
+			return nil, err
+		}
 
-	// phi(n) = (p - 1)(q - 1) because p and q - primes
-	phiN := new(big.Int).Mul(new(big.Int).Sub(p, big.NewInt(1)), new(big.Int).Sub(q, big.NewInt(1)))
+		// e should be invertible modulo p - 1
+		if _, ok := seen[p.String()]; ok || new(big.Int).GCD(nil, nil, e, new(big.Int).Sub(p, big.NewInt(1))).Cmp(big.NewInt(1)) != 0 {
+			continue
+		}
+
+		seen[p.String()] = struct{}{}
+		list = append(list, p)
+	}
+
+	n := big.NewInt(1)
+	phiN := big.NewInt(1)
+	for _, p := range list {
+		n.Mul(n, p)
+		// phi(n) = (p_1 - 1)...(p_k - 1) because p_i - primes
+		phiN.Mul(phiN, new(big.Int).Sub(p, big.NewInt(1)))
+	}
 
 	// Euler's theorem can be used
 	d := new(big.Int).ModInverse(e, phiN)
 
-	return &PrivateKey{
+	key := &PrivateKey{
 		PublicKey: &PublicKey{
 			n: n,
 		},
-		P: p,
-		Q: q,
+		P: list[0],
+		Q: list[1],
 		D: d,
-	}, nil
+	}
+
+	for _, r := range list[2:] {
+		key.Extra = append(key.Extra, &CRTPrime{R: r})
+	}
+
+	key.Precompute()
+	return key, nil
 }
 
 func Encrypt(msg *big.Int, pk *PublicKey) *big.Int {
 	return new(big.Int).Exp(msg, e, pk.n)
 }
 
+// Decrypt decrypts the cypher using DecryptCRT if CRT values are present.
+// In case of detected fault the result is computed with the full exponent d.
 func Decrypt(cypher *big.Int, prv *PrivateKey) *big.Int {
+	if prv.DP != nil {
+		if m, err := DecryptCRT(cypher, prv); err == nil {
+			return m
+		}
+	}
+
 	return new(big.Int).Exp(cypher, prv.D, prv.n)
 }
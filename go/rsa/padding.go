@@ -0,0 +1,201 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `padding.go` implements RSA-OAEP and PKCS#1 v1.5 encryption schemes from RFC 8017
+// (https://www.rfc-editor.org/rfc/rfc8017) with SHA-256 and MGF1-SHA256.
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrMessageTooLong = errors.New("message is too long for the key size")
+	ErrDecryption     = errors.New("decryption error")
+	ErrVerification   = errors.New("signature verification error")
+)
+
+// Size returns the modulus size in bytes
+func (pk *PublicKey) Size() int {
+	return (pk.n.BitLen() + 7) / 8
+}
+
+// EncryptOAEP encrypts the message using RSAES-OAEP with SHA-256. Label can be empty.
+func EncryptOAEP(msg, label []byte, pk *PublicKey) ([]byte, error) {
+	k := pk.Size()
+	hLen := sha256.Size
+
+	if len(msg) > k-2*hLen-2 {
+		return nil, ErrMessageTooLong
+	}
+
+	// EM = 0x00 || maskedSeed || maskedDB
+	em := make([]byte, k)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+
+	// DB = lHash || PS || 0x01 || M
+	lHash := sha256.Sum256(label)
+	copy(db, lHash[:])
+	db[len(db)-len(msg)-1] = 0x01
+	copy(db[len(db)-len(msg):], msg)
+
+	if _, err := io.ReadFull(rand.Reader, seed); err != nil {
+		return nil, err
+	}
+
+	xor(db, mgf1(sha256.New, seed, len(db)))
+	xor(seed, mgf1(sha256.New, db, hLen))
+
+	return encrypt(em, pk), nil
+}
+
+// DecryptOAEP decrypts the RSAES-OAEP cypher with SHA-256. Label should be the same as in EncryptOAEP.
+func DecryptOAEP(cypher, label []byte, prv *PrivateKey) ([]byte, error) {
+	k := prv.Size()
+	hLen := sha256.Size
+
+	if len(cypher) != k || k < 2*hLen+2 {
+		return nil, ErrDecryption
+	}
+
+	em, err := decrypt(cypher, prv)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+
+	xor(seed, mgf1(sha256.New, db, hLen))
+	xor(db, mgf1(sha256.New, seed, len(db)))
+
+	lHash := sha256.Sum256(label)
+	valid := subtle.ConstantTimeByteEq(em[0], 0)
+	valid &= subtle.ConstantTimeCompare(db[:hLen], lHash[:])
+
+	// find the first 0x01 after zero padding in constant time
+	lookingForIndex, index, invalid := 1, 0, 0
+	for i := hLen; i < len(db); i++ {
+		isZero := subtle.ConstantTimeByteEq(db[i], 0)
+		isOne := subtle.ConstantTimeByteEq(db[i], 1)
+		index = subtle.ConstantTimeSelect(lookingForIndex&isOne, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(isOne, 0, lookingForIndex)
+		invalid = subtle.ConstantTimeSelect(lookingForIndex&^isZero, 1, invalid)
+	}
+
+	if valid&^invalid&^lookingForIndex != 1 {
+		return nil, ErrDecryption
+	}
+
+	return db[index+1:], nil
+}
+
+// EncryptPKCS1v15 encrypts the message using RSAES-PKCS1-v1_5. Use it only for the legacy interoperability.
+func EncryptPKCS1v15(msg []byte, pk *PublicKey) ([]byte, error) {
+	k := pk.Size()
+	if len(msg) > k-11 {
+		return nil, ErrMessageTooLong
+	}
+
+	// EM = 0x00 || 0x02 || PS || 0x00 || M, where PS consists of non-zero random bytes
+	em := make([]byte, k)
+	em[1] = 0x02
+	ps := em[2 : k-len(msg)-1]
+	if err := nonZeroRandom(ps); err != nil {
+		return nil, err
+	}
+
+	copy(em[k-len(msg):], msg)
+	return encrypt(em, pk), nil
+}
+
+// DecryptPKCS1v15 decrypts the RSAES-PKCS1-v1_5 cypher
+func DecryptPKCS1v15(cypher []byte, prv *PrivateKey) ([]byte, error) {
+	k := prv.Size()
+	if len(cypher) != k || k < 11 {
+		return nil, ErrDecryption
+	}
+
+	em, err := decrypt(cypher, prv)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := subtle.ConstantTimeByteEq(em[0], 0) & subtle.ConstantTimeByteEq(em[1], 2)
+
+	lookingForIndex, index := 1, 0
+	for i := 2; i < len(em); i++ {
+		isZero := subtle.ConstantTimeByteEq(em[i], 0)
+		index = subtle.ConstantTimeSelect(lookingForIndex&isZero, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(isZero, 0, lookingForIndex)
+	}
+
+	// PS should be at least 8 bytes long
+	valid &= subtle.ConstantTimeLessOrEq(10, index)
+	if valid&^lookingForIndex != 1 {
+		return nil, ErrDecryption
+	}
+
+	return em[index+1:], nil
+}
+
+// encrypt applies RSA public operation to the encoded message and returns k bytes
+func encrypt(em []byte, pk *PublicKey) []byte {
+	c := Encrypt(new(big.Int).SetBytes(em), pk)
+	return c.FillBytes(make([]byte, pk.Size()))
+}
+
+// decrypt applies RSA private operation to the k bytes cypher and returns k bytes
+func decrypt(cypher []byte, prv *PrivateKey) ([]byte, error) {
+	c := new(big.Int).SetBytes(cypher)
+	if c.Cmp(prv.n) >= 0 {
+		return nil, ErrDecryption
+	}
+
+	m := Decrypt(c, prv)
+	return m.FillBytes(make([]byte, prv.Size())), nil
+}
+
+// mgf1 is the mask generation function based on the given hash
+func mgf1(newHash func() hash.Hash, seed []byte, length int) []byte {
+	res := make([]byte, 0, length+newHash().Size())
+	for counter := uint32(0); len(res) < length; counter++ {
+		h := newHash()
+		h.Write(seed)
+		h.Write([]byte{byte(counter >> 24), byte(counter >> 16), byte(counter >> 8), byte(counter)})
+		res = h.Sum(res)
+	}
+
+	return res[:length]
+}
+
+func xor(dst, mask []byte) {
+	for i := range dst {
+		dst[i] ^= mask[i]
+	}
+}
+
+func nonZeroRandom(b []byte) error {
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return err
+	}
+
+	for i := range b {
+		for b[i] == 0 {
+			if _, err := io.ReadFull(rand.Reader, b[i:i+1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
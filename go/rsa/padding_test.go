@@ -0,0 +1,158 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestOAEP(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	std := key.ToStd()
+	msg := []byte("Hello World")
+	label := []byte("label")
+
+	cypher, err := EncryptOAEP(msg, label, key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := stdrsa.DecryptOAEP(sha256.New(), nil, std, cypher, label)
+	if err != nil {
+		panic(err)
+	}
+
+	if !bytes.Equal(res, msg) {
+		panic("message decrypted by crypto/rsa is not equal")
+	}
+
+	cypher, err = stdrsa.EncryptOAEP(sha256.New(), rand.Reader, &std.PublicKey, msg, label)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err = DecryptOAEP(cypher, label, key)
+	if err != nil {
+		panic(err)
+	}
+
+	if !bytes.Equal(res, msg) {
+		panic("message encrypted by crypto/rsa is not equal")
+	}
+
+	if _, err := DecryptOAEP(cypher, []byte("another label"), key); err != ErrDecryption {
+		panic("wrong label was accepted")
+	}
+}
+
+func TestPKCS1v15Encryption(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	std := key.ToStd()
+	msg := []byte("Hello World")
+
+	cypher, err := EncryptPKCS1v15(msg, key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := stdrsa.DecryptPKCS1v15(nil, std, cypher)
+	if err != nil {
+		panic(err)
+	}
+
+	if !bytes.Equal(res, msg) {
+		panic("message decrypted by crypto/rsa is not equal")
+	}
+
+	cypher, err = stdrsa.EncryptPKCS1v15(rand.Reader, &std.PublicKey, msg)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err = DecryptPKCS1v15(cypher, key)
+	if err != nil {
+		panic(err)
+	}
+
+	if !bytes.Equal(res, msg) {
+		panic("message encrypted by crypto/rsa is not equal")
+	}
+}
+
+func TestPSS(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	std := key.ToStd()
+	msg := []byte("Hello World")
+	hash := sha256.Sum256(msg)
+
+	sig, err := SignPSS(msg, key)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := stdrsa.VerifyPSS(&std.PublicKey, crypto.SHA256, hash[:], sig, nil); err != nil {
+		panic(err)
+	}
+
+	// crypto/rsa uses the maximal salt length by default
+	sig, err = stdrsa.SignPSS(rand.Reader, std, crypto.SHA256, hash[:], nil)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := VerifyPSS(msg, sig, key.PublicKey); err != nil {
+		panic(err)
+	}
+
+	if err := VerifyPSS([]byte("Hello world"), sig, key.PublicKey); err != ErrVerification {
+		panic("signature for another message was accepted")
+	}
+}
+
+func TestPKCS1v15Signature(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	std := key.ToStd()
+	msg := []byte("Hello World")
+	hash := sha256.Sum256(msg)
+
+	sig, err := SignPKCS1v15(msg, key)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := stdrsa.VerifyPKCS1v15(&std.PublicKey, crypto.SHA256, hash[:], sig); err != nil {
+		panic(err)
+	}
+
+	sig, err = stdrsa.SignPKCS1v15(rand.Reader, std, crypto.SHA256, hash[:])
+	if err != nil {
+		panic(err)
+	}
+
+	if err := VerifyPKCS1v15(msg, sig, key.PublicKey); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package rsa
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `pss.go` implements RSASSA-PSS and RSASSA-PKCS1-v1_5 signature schemes from RFC 8017
+// (https://www.rfc-editor.org/rfc/rfc8017) with SHA-256 and MGF1-SHA256.
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"math/big"
+)
+
+// sha256DigestInfo is the DER encoding of DigestInfo prefix for SHA-256
+var sha256DigestInfo = []byte{0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20}
+
+// SignPSS signs the message using RSASSA-PSS with SHA-256 and salt length equal to the hash size
+func SignPSS(msg []byte, prv *PrivateKey) ([]byte, error) {
+	salt := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	mHash := sha256.Sum256(msg)
+	em, err := emsaPSSEncode(sha256.New, mHash[:], prv.n.BitLen()-1, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return sign(em, prv)
+}
+
+// VerifyPSS verifies RSASSA-PSS signature with SHA-256. Salt length is detected automatically.
+func VerifyPSS(msg, sig []byte, pk *PublicKey) error {
+	m, err := verify(sig, pk)
+	if err != nil {
+		return err
+	}
+
+	mHash := sha256.Sum256(msg)
+	return pssVerify(sha256.New, mHash[:], m, pk.n.BitLen()-1, -1)
+}
+
+// SignPKCS1v15 signs the message using RSASSA-PKCS1-v1_5 with SHA-256. Use it only for the legacy interoperability.
+func SignPKCS1v15(msg []byte, prv *PrivateKey) ([]byte, error) {
+	em, err := emsaPKCS1v15Encode(msg, prv.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	return sign(em, prv)
+}
+
+// VerifyPKCS1v15 verifies RSASSA-PKCS1-v1_5 signature with SHA-256
+func VerifyPKCS1v15(msg, sig []byte, pk *PublicKey) error {
+	m, err := verify(sig, pk)
+	if err != nil {
+		return err
+	}
+
+	expected, err := emsaPKCS1v15Encode(msg, pk.Size())
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(m.FillBytes(make([]byte, pk.Size())), expected) {
+		return ErrVerification
+	}
+
+	return nil
+}
+
+// emsaPSSEncode returns EM = maskedDB || H || 0xbc, where H = Hash(0x00*8 || mHash || salt) and DB = PS || 0x01 || salt
+func emsaPSSEncode(newHash func() hash.Hash, mHash []byte, emBits int, salt []byte) ([]byte, error) {
+	hLen := newHash().Size()
+	emLen := (emBits + 7) / 8
+
+	if emLen < hLen+len(salt)+2 {
+		return nil, ErrMessageTooLong
+	}
+
+	h := pssHash(newHash, mHash, salt)
+
+	em := make([]byte, emLen)
+	db := em[:emLen-hLen-1]
+	db[len(db)-len(salt)-1] = 0x01
+	copy(db[len(db)-len(salt):], salt)
+
+	xor(db, mgf1(newHash, h, len(db)))
+	db[0] &= 0xff >> (8*emLen - emBits)
+
+	copy(em[len(db):], h)
+	em[emLen-1] = 0xbc
+	return em, nil
+}
+
+// pssVerify checks that m is the integer representation of EMSA-PSS encoded message
+func pssVerify(newHash func() hash.Hash, mHash []byte, m *big.Int, emBits, saltLen int) error {
+	emLen := (emBits + 7) / 8
+	if m.BitLen() > emBits {
+		return ErrVerification
+	}
+
+	return emsaPSSVerify(newHash, mHash, m.FillBytes(make([]byte, emLen)), emBits, saltLen)
+}
+
+// emsaPSSVerify verifies EMSA-PSS encoded message, salt length is detected automatically if saltLen < 0
+func emsaPSSVerify(newHash func() hash.Hash, mHash, em []byte, emBits, saltLen int) error {
+	hLen := newHash().Size()
+	emLen := len(em)
+
+	if emLen < hLen+2 || em[emLen-1] != 0xbc {
+		return ErrVerification
+	}
+
+	db := append([]byte{}, em[:emLen-hLen-1]...)
+	h := em[emLen-hLen-1 : emLen-1]
+
+	mask := byte(0xff >> (8*emLen - emBits))
+	if db[0]&^mask != 0 {
+		return ErrVerification
+	}
+
+	xor(db, mgf1(newHash, h, len(db)))
+	db[0] &= mask
+
+	// DB = 0x00 ... 0x00 || 0x01 || salt
+	index := bytes.IndexByte(db, 0x01)
+	if index < 0 || !bytes.Equal(db[:index], make([]byte, index)) {
+		return ErrVerification
+	}
+
+	if saltLen >= 0 && len(db)-index-1 != saltLen {
+		return ErrVerification
+	}
+
+	if !bytes.Equal(pssHash(newHash, mHash, db[index+1:]), h) {
+		return ErrVerification
+	}
+
+	return nil
+}
+
+func pssHash(newHash func() hash.Hash, mHash, salt []byte) []byte {
+	h := newHash()
+	h.Write(make([]byte, 8))
+	h.Write(mHash)
+	h.Write(salt)
+	return h.Sum(nil)
+}
+
+// emsaPKCS1v15Encode returns EM = 0x00 || 0x01 || PS || 0x00 || DigestInfo, where PS consists of 0xff bytes
+func emsaPKCS1v15Encode(msg []byte, k int) ([]byte, error) {
+	hash := sha256.Sum256(msg)
+	t := append(append([]byte{}, sha256DigestInfo...), hash[:]...)
+
+	if k < len(t)+11 {
+		return nil, ErrMessageTooLong
+	}
+
+	em := make([]byte, k)
+	em[1] = 0x01
+	for i := 2; i < k-len(t)-1; i++ {
+		em[i] = 0xff
+	}
+
+	copy(em[k-len(t):], t)
+	return em, nil
+}
+
+// sign applies RSA private operation to the encoded message and returns k bytes
+func sign(em []byte, prv *PrivateKey) ([]byte, error) {
+	m := new(big.Int).SetBytes(em)
+	if m.Cmp(prv.n) >= 0 {
+		return nil, ErrMessageTooLong
+	}
+
+	return Decrypt(m, prv).FillBytes(make([]byte, prv.Size())), nil
+}
+
+// verify applies RSA public operation to the k bytes signature
+func verify(sig []byte, pk *PublicKey) (*big.Int, error) {
+	if len(sig) != pk.Size() {
+		return nil, ErrVerification
+	}
+
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(pk.n) >= 0 {
+		return nil, ErrVerification
+	}
+
+	return Encrypt(s, pk), nil
+}
@@ -0,0 +1,211 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `accumulator.go` implements the dynamic RSA accumulator with incremental additions and deletions.
+// Membership witnesses are updated without the full set following Li, Li, Xue
+// "Universal Accumulators with Efficient Nonmembership Proofs" (https://eprint.iacr.org/2007/248):
+//   - after adding y: w' = w^y
+//   - after deleting y with the new value A': w' = w^b * A'^a, where a*x + b*y = 1
+package rsaacc
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/math"
+)
+
+var (
+	ErrNotPrime        = errors.New("accumulated value has to be prime")
+	ErrAlreadyMember   = errors.New("value is already accumulated")
+	ErrNotMember       = errors.New("value is not accumulated")
+	ErrInvalidWitness  = errors.New("invalid membership witness")
+	ErrNoTrapdoor      = errors.New("trapdoor is required")
+	ErrInvalidUpdate   = errors.New("invalid accumulator update")
+	ErrDeletedWitness  = errors.New("witness value was deleted")
+	ErrNotCoprimeValue = errors.New("values should be coprime")
+	ErrDuplicateValue  = errors.New("values should be distinct")
+)
+
+// Trapdoor is the factorization of the accumulator modulus N = P*Q
+type Trapdoor struct {
+	P, Q *big.Int
+}
+
+// phi returns (P - 1)(Q - 1)
+func (t *Trapdoor) phi() *big.Int {
+	return new(big.Int).Mul(new(big.Int).Sub(t.P, big.NewInt(1)), new(big.Int).Sub(t.Q, big.NewInt(1)))
+}
+
+// Update describes one change of the accumulator: the Added or Deleted value and the new accumulator Value.
+// Witness holders apply updates to their witnesses in the same order.
+type Update struct {
+	Added   *big.Int
+	Deleted *big.Int
+	Value   *big.Int
+}
+
+// Accumulator is the dynamic RSA accumulator A = G^(x_1*...*x_k) mod N of prime values.
+// Manager without trapdoor can delete values only using their witnesses.
+// It works in the RSA group only, use Accumulate and VerifyMembership for ClassGroup.
+type Accumulator struct {
+	N, G  *big.Int
+	Value *big.Int
+
+	trapdoor *Trapdoor
+	members  map[string]*big.Int
+}
+
+// NewAccumulator returns the empty accumulator with Value = G
+func NewAccumulator(n, g *big.Int) *Accumulator {
+	return &Accumulator{
+		N:       new(big.Int).Set(n),
+		G:       new(big.Int).Set(g),
+		Value:   new(big.Int).Set(g),
+		members: make(map[string]*big.Int),
+	}
+}
+
+// NewAccumulatorWithTrapdoor returns the empty accumulator with the modulus P*Q that can delete values without witnesses
+func NewAccumulatorWithTrapdoor(t *Trapdoor, g *big.Int) *Accumulator {
+	acc := NewAccumulator(new(big.Int).Mul(t.P, t.Q), g)
+	acc.trapdoor = t
+	return acc
+}
+
+// Contains returns true if the value is accumulated
+func (a *Accumulator) Contains(x *big.Int) bool {
+	_, ok := a.members[x.String()]
+	return ok
+}
+
+// Add accumulates the prime value: A' = A^x
+func (a *Accumulator) Add(x *big.Int) (*Update, error) {
+	if !math.BailliePSW(x) {
+		return nil, ErrNotPrime
+	}
+
+	if a.Contains(x) {
+		return nil, ErrAlreadyMember
+	}
+
+	a.Value = new(big.Int).Exp(a.Value, x, a.N)
+	a.members[x.String()] = new(big.Int).Set(x)
+
+	return &Update{Added: new(big.Int).Set(x), Value: new(big.Int).Set(a.Value)}, nil
+}
+
+// Delete removes the value using the trapdoor: A' = A^(x^-1 mod phi(N))
+func (a *Accumulator) Delete(x *big.Int) (*Update, error) {
+	if !a.hasTrapdoor() {
+		return nil, ErrNoTrapdoor
+	}
+
+	if !a.Contains(x) {
+		return nil, ErrNotMember
+	}
+
+	w, err := a.trapdoorRoot(x)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.delete(x, w), nil
+}
+
+// DeleteWithWitness removes the value without trapdoor: the new accumulator is the witness of x
+func (a *Accumulator) DeleteWithWitness(x, witness *big.Int) (*Update, error) {
+	if !a.Contains(x) {
+		return nil, ErrNotMember
+	}
+
+	if !Verify(a.N, witness, x, a.Value) {
+		return nil, ErrInvalidWitness
+	}
+
+	return a.delete(x, witness), nil
+}
+
+func (a *Accumulator) delete(x, value *big.Int) *Update {
+	a.Value = new(big.Int).Set(value)
+	delete(a.members, x.String())
+
+	return &Update{Deleted: new(big.Int).Set(x), Value: new(big.Int).Set(a.Value)}
+}
+
+// Witness returns the membership witness w: w^x = A. It costs one exponentiation with the trapdoor,
+// otherwise w = G^(product of other values) is computed.
+func (a *Accumulator) Witness(x *big.Int) (*big.Int, error) {
+	if !a.Contains(x) {
+		return nil, ErrNotMember
+	}
+
+	if a.hasTrapdoor() {
+		return a.trapdoorRoot(x)
+	}
+
+	prod := big.NewInt(1)
+	for k, v := range a.members {
+		if k != x.String() {
+			prod.Mul(prod, v)
+		}
+	}
+
+	return new(big.Int).Exp(a.G, prod, a.N), nil
+}
+
+// hasTrapdoor returns true if the trapdoor is present and was not destroyed
+func (a *Accumulator) hasTrapdoor() bool {
+	return a.trapdoor != nil && a.trapdoor.P != nil && a.trapdoor.Q != nil
+}
+
+// trapdoorRoot returns A^(x^-1 mod phi(N))
+func (a *Accumulator) trapdoorRoot(x *big.Int) (*big.Int, error) {
+	inv := new(big.Int).ModInverse(x, a.trapdoor.phi())
+	if inv == nil {
+		return nil, ErrNotCoprimeValue
+	}
+
+	return new(big.Int).Exp(a.Value, inv, a.N), nil
+}
+
+// UpdateWitness returns the membership witness of x after the accumulator update
+func UpdateWitness(n, witness, x *big.Int, upd *Update) (*big.Int, error) {
+	switch {
+	case upd.Added != nil && upd.Deleted == nil:
+		return UpdateWitnessOnAdd(n, witness, upd.Added), nil
+	case upd.Deleted != nil && upd.Added == nil:
+		return UpdateWitnessOnDelete(n, witness, x, upd.Deleted, upd.Value)
+	}
+
+	return nil, ErrInvalidUpdate
+}
+
+// UpdateWitnessOnAdd returns w' = w^y after adding y
+func UpdateWitnessOnAdd(n, witness, added *big.Int) *big.Int {
+	return new(big.Int).Exp(witness, added, n)
+}
+
+// UpdateWitnessOnDelete returns w' = w^b * A'^a after deleting y, where a*x + b*y = 1 and A' is the new accumulator:
+// w'^x = A^b * A^(a*x/y) = A^((a*x + b*y)/y) = A'
+func UpdateWitnessOnDelete(n, witness, x, deleted, value *big.Int) (*big.Int, error) {
+	if x.Cmp(deleted) == 0 {
+		return nil, ErrDeletedWitness
+	}
+
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, x, deleted).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrNotCoprimeValue
+	}
+
+	// negative exponents are handled by Exp with the inverse of the base
+	wb := new(big.Int).Exp(witness, b, n)
+	va := new(big.Int).Exp(value, a, n)
+	if wb == nil || va == nil {
+		return nil, ErrInvalidWitness
+	}
+
+	return wb.Mul(wb, va).Mod(wb, n), nil
+}
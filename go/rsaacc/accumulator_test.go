@@ -0,0 +1,128 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsaacc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func randPrimes(count int) []*big.Int {
+	res := make([]*big.Int, 0, count)
+	for len(res) < count {
+		p, err := rand.Prime(rand.Reader, 64)
+		if err != nil {
+			panic(err)
+		}
+
+		res = append(res, p)
+	}
+
+	return res
+}
+
+func TestAccumulator(t *testing.T) {
+	n, trapdoor, err := GenTrapdoor(ModulusBits2048)
+	if err != nil {
+		panic(err)
+	}
+
+	g := big.NewInt(3)
+
+	for _, acc := range []*Accumulator{NewAccumulator(n, g), NewAccumulatorWithTrapdoor(trapdoor, g)} {
+		values := randPrimes(5)
+
+		// holder of values[0] keeps the witness up to date using only updates
+		var witness *big.Int
+		for i, x := range values {
+			upd, err := acc.Add(x)
+			if err != nil {
+				panic(err)
+			}
+
+			if i == 0 {
+				witness = new(big.Int).Set(g)
+				continue
+			}
+
+			if witness, err = UpdateWitness(n, witness, values[0], upd); err != nil {
+				panic(err)
+			}
+		}
+
+		if acc.Value.Cmp(Build(n, g, values...)) != 0 {
+			panic("accumulator value is not equal to the built one")
+		}
+
+		if !Verify(n, witness, values[0], acc.Value) {
+			panic("updated witness is invalid after additions")
+		}
+
+		// the witness of two members proves their product that was never added
+		pair := Build(n, g, values[2:]...)
+		if Verify(n, pair, new(big.Int).Mul(values[0], values[1]), acc.Value) {
+			panic("membership proof for composite is valid")
+		}
+
+		if _, err := acc.Add(values[1]); err != ErrAlreadyMember {
+			panic("value was added twice")
+		}
+
+		var upd *Update
+		var err error
+
+		if acc.trapdoor != nil {
+			upd, err = acc.Delete(values[2])
+		} else {
+			w, err := acc.Witness(values[2])
+			if err != nil {
+				panic(err)
+			}
+
+			if _, err := acc.Delete(values[2]); err != ErrNoTrapdoor {
+				panic("value was deleted without trapdoor")
+			}
+
+			upd, err = acc.DeleteWithWitness(values[2], w)
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		if witness, err = UpdateWitness(n, witness, values[0], upd); err != nil {
+			panic(err)
+		}
+
+		rest := []*big.Int{values[0], values[1], values[3], values[4]}
+		if acc.Value.Cmp(Build(n, g, rest...)) != 0 {
+			panic("accumulator value is invalid after deletion")
+		}
+
+		if !Verify(n, witness, values[0], acc.Value) {
+			panic("updated witness is invalid after deletion")
+		}
+
+		for _, x := range rest {
+			w, err := acc.Witness(x)
+			if err != nil {
+				panic(err)
+			}
+
+			if !Verify(n, w, x, acc.Value) {
+				panic("invalid witness")
+			}
+		}
+
+		if _, err := acc.Witness(values[2]); err != ErrNotMember {
+			panic("witness of deleted value was returned")
+		}
+
+		if _, err := UpdateWitness(n, witness, values[2], upd); err != ErrDeletedWitness {
+			panic("witness of deleted value was updated")
+		}
+	}
+}
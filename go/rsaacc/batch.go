@@ -0,0 +1,130 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `batch.go` implements batched membership proofs: one aggregated witness w for the set of values
+// with w^(x_1*...*x_k) = A and Wesolowski PoE of this exponentiation, so the verifier computes
+// the product modulo 128-bit prime instead of exponentiation to the full product.
+package rsaacc
+
+import (
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/math"
+)
+
+// BatchProof proves membership of several values: Witness^(x_1*...*x_k) = A
+type BatchProof struct {
+	Witness *big.Int
+	PoE     *PoEProof
+}
+
+// BatchWitness returns the aggregated membership witness of the values
+func (a *Accumulator) BatchWitness(xs ...*big.Int) (*big.Int, error) {
+	prod := big.NewInt(1)
+	excluded := make(map[string]struct{}, len(xs))
+
+	for _, x := range xs {
+		if !a.Contains(x) {
+			return nil, ErrNotMember
+		}
+
+		if _, ok := excluded[x.String()]; ok {
+			return nil, ErrDuplicateValue
+		}
+
+		excluded[x.String()] = struct{}{}
+		prod.Mul(prod, x)
+	}
+
+	if a.hasTrapdoor() {
+		return a.trapdoorRoot(prod)
+	}
+
+	others := big.NewInt(1)
+	for k, v := range a.members {
+		if _, ok := excluded[k]; !ok {
+			others.Mul(others, v)
+		}
+	}
+
+	return new(big.Int).Exp(a.G, others, a.N), nil
+}
+
+// ProveBatch returns the aggregated witness of the values with PoE
+func (a *Accumulator) ProveBatch(xs ...*big.Int) (*BatchProof, error) {
+	w, err := a.BatchWitness(xs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchProof{Witness: w, PoE: ProvePoE(a.N, w, product(xs), a.Value)}, nil
+}
+
+// AggregateWitnesses combines witnesses w1 of x1 and w2 of x2 for coprime x1, x2 into the witness of x1*x2
+// using Shamir's trick: w = w1^b * w2^a, where a*x1 + b*x2 = 1
+func AggregateWitnesses(n, w1, x1, w2, x2 *big.Int) (*big.Int, error) {
+	if new(big.Int).Exp(w1, x1, n).Cmp(new(big.Int).Exp(w2, x2, n)) != 0 {
+		return nil, ErrInvalidWitness
+	}
+
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, x1, x2).Cmp(big.NewInt(1)) != 0 {
+		return nil, ErrNotCoprimeValue
+	}
+
+	// negative exponents are handled by Exp with the inverse of the base
+	res := new(big.Int).Exp(w1, b, n)
+	t := new(big.Int).Exp(w2, a, n)
+	if res == nil || t == nil {
+		return nil, ErrInvalidWitness
+	}
+
+	return res.Mul(res, t).Mod(res, n), nil
+}
+
+// ProveAggregated returns the batch proof for the witnesses of the values aggregated with AggregateWitnesses
+func ProveAggregated(n, value *big.Int, witnesses, xs []*big.Int) (*BatchProof, error) {
+	if len(witnesses) == 0 || len(witnesses) != len(xs) {
+		return nil, ErrInvalidWitness
+	}
+
+	w, x := witnesses[0], xs[0]
+	for i := 1; i < len(xs); i++ {
+		var err error
+		if w, err = AggregateWitnesses(n, w, x, witnesses[i], xs[i]); err != nil {
+			return nil, err
+		}
+
+		x = new(big.Int).Mul(x, xs[i])
+	}
+
+	return &BatchProof{Witness: w, PoE: ProvePoE(n, w, x, value)}, nil
+}
+
+// VerifyBatch checks the membership of all values with one PoE verification. Values should be prime.
+func VerifyBatch(n, value *big.Int, xs []*big.Int, proof *BatchProof) bool {
+	if proof == nil || proof.Witness == nil || len(xs) == 0 {
+		return false
+	}
+
+	// x = 1 is a member of any accumulator with the value itself as a witness
+	// and the product of members is proven with their aggregated witness
+	for _, x := range xs {
+		if x == nil || !math.BailliePSW(x) {
+			return false
+		}
+	}
+
+	return VerifyPoE(n, proof.Witness, product(xs), value, proof.PoE)
+}
+
+func product(xs []*big.Int) *big.Int {
+	res := big.NewInt(1)
+	for _, x := range xs {
+		res.Mul(res, x)
+	}
+
+	return res
+}
@@ -0,0 +1,88 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsaacc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	n, trapdoor, err := GenTrapdoor(ModulusBits2048)
+	if err != nil {
+		panic(err)
+	}
+
+	g := big.NewInt(3)
+
+	for _, acc := range []*Accumulator{NewAccumulator(n, g), NewAccumulatorWithTrapdoor(trapdoor, g)} {
+		values := randPrimes(10)
+		for _, x := range values {
+			if _, err := acc.Add(x); err != nil {
+				panic(err)
+			}
+		}
+
+		batch := values[2:8]
+
+		proof, err := acc.ProveBatch(batch...)
+		if err != nil {
+			panic(err)
+		}
+
+		if !VerifyBatch(n, acc.Value, batch, proof) {
+			panic("batch proof is invalid")
+		}
+
+		if VerifyBatch(n, acc.Value, values[1:8], proof) {
+			panic("batch proof is valid for another set")
+		}
+
+		witnesses := make([]*big.Int, 0, len(batch))
+		for _, x := range batch {
+			w, err := acc.Witness(x)
+			if err != nil {
+				panic(err)
+			}
+
+			witnesses = append(witnesses, w)
+		}
+
+		aggregated, err := ProveAggregated(n, acc.Value, witnesses, batch)
+		if err != nil {
+			panic(err)
+		}
+
+		if aggregated.Witness.Cmp(proof.Witness) != 0 || !VerifyBatch(n, acc.Value, batch, aggregated) {
+			panic("aggregated proof is invalid")
+		}
+
+		if _, err := acc.ProveBatch(values[0], HashToPrime([]byte("missing"))); err != ErrNotMember {
+			panic("batch proof returned for missing value")
+		}
+
+		// trivial forgery: value^1 = value
+		one := big.NewInt(1)
+		forged := &BatchProof{Witness: acc.Value, PoE: ProvePoE(n, acc.Value, one, acc.Value)}
+		if VerifyBatch(n, acc.Value, []*big.Int{one}, forged) {
+			panic("batch proof for one is valid")
+		}
+
+		if Verify(n, acc.Value, one, acc.Value) {
+			panic("membership proof for one is valid")
+		}
+
+		// batch proof of two members proves their product
+		pair, err := acc.ProveBatch(values[0], values[1])
+		if err != nil {
+			panic(err)
+		}
+
+		composite := new(big.Int).Mul(values[0], values[1])
+		if VerifyBatch(n, acc.Value, []*big.Int{composite}, pair) {
+			panic("batch proof for composite is valid")
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `classgroup.go` implements the class group of imaginary quadratic order with negative prime discriminant D.
+// Elements are reduced binary quadratic forms (a, b, c) with b^2 - 4ac = D. The group order is the class number
+// that is hard to compute for large D, so the group is of unknown order without any trusted setup.
+// Composition and reduction follow Chia Network "Binary quadratic forms" (https://github.com/Chia-Network/vdf-competition/blob/master/classgroups.pdf).
+package rsaacc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/math"
+)
+
+// DiscriminantBits1665 gives about 128-bit security for class groups
+const DiscriminantBits1665 = 1665
+
+var (
+	ErrInvalidDiscriminant = errors.New("discriminant should be negative prime = 1 mod 8")
+	ErrNoSolution          = errors.New("linear congruence has no solution")
+	ErrInvalidForm         = errors.New("form is not reduced or has another discriminant")
+)
+
+// Form is the binary quadratic form ax^2 + bxy + cy^2
+type Form struct {
+	A, B, C *big.Int
+}
+
+// ClassGroup is the class group with discriminant D and *Form elements
+type ClassGroup struct {
+	D *big.Int
+}
+
+var _ Group = &ClassGroup{}
+
+// NewClassGroup derives the discriminant of the given size from the public seed: D = -p, where p = 7 mod 8 is prime.
+// Anyone can check that the discriminant was derived from the seed, so no trusted setup is required.
+func NewClassGroup(seed []byte, bits int) (*ClassGroup, error) {
+	if bits < 8 {
+		return nil, ErrInvalidDiscriminant
+	}
+
+	buf := make([]byte, len(seed)+8)
+	copy(buf, seed)
+
+	var data []byte
+	for counter := uint64(0); len(data)*8 < bits; counter++ {
+		binary.BigEndian.PutUint64(buf[len(seed):], counter)
+		h := sha256.Sum256(buf)
+		data = append(data, h[:]...)
+	}
+
+	p := new(big.Int).SetBytes(data)
+	p.Rsh(p, uint(len(data)*8-bits))
+	p.SetBit(p, bits-1, 1)
+
+	// p = 7 mod 8
+	p.Sub(p, new(big.Int).Mod(p, big.NewInt(8)))
+	p.Add(p, big.NewInt(7))
+
+	for !math.BailliePSW(p) {
+		p.Add(p, big.NewInt(8))
+	}
+
+	return NewClassGroupFromDiscriminant(new(big.Int).Neg(p))
+}
+
+// NewClassGroupFromDiscriminant returns the class group with negative prime discriminant D = 1 mod 8
+func NewClassGroupFromDiscriminant(d *big.Int) (*ClassGroup, error) {
+	p := new(big.Int).Neg(d)
+	if d.Sign() >= 0 || new(big.Int).Mod(d, big.NewInt(8)).Cmp(big.NewInt(1)) != 0 || !math.BailliePSW(p) {
+		return nil, ErrInvalidDiscriminant
+	}
+
+	return &ClassGroup{D: new(big.Int).Set(d)}, nil
+}
+
+// NewForm returns the reduced form (a, b, c) with c = (b^2 - D) / 4a
+func (g *ClassGroup) NewForm(a, b *big.Int) (*Form, error) {
+	num := new(big.Int).Sub(new(big.Int).Mul(b, b), g.D)
+	den := new(big.Int).Lsh(a, 2)
+
+	if a.Sign() <= 0 || new(big.Int).Mod(num, den).Sign() != 0 {
+		return nil, ErrInvalidDiscriminant
+	}
+
+	return reduce(&Form{A: new(big.Int).Set(a), B: new(big.Int).Set(b), C: num.Div(num, den)}), nil
+}
+
+// Generator returns the form (2, 1, (1 - D)/8)
+func (g *ClassGroup) Generator() math.GroupElement {
+	f, err := g.NewForm(big.NewInt(2), big.NewInt(1))
+	if err != nil {
+		panic(err)
+	}
+
+	return f
+}
+
+// Identity returns the principal form (1, 1, (1 - D)/4)
+func (g *ClassGroup) Identity() math.GroupElement {
+	f, err := g.NewForm(big.NewInt(1), big.NewInt(1))
+	if err != nil {
+		panic(err)
+	}
+
+	return f
+}
+
+// Valid checks that a is the reduced form of the group: b^2 - 4ac = D, -a < b <= a <= c and b >= 0 if a = c.
+// Mul, Exp, Inv and Bytes return nil for invalid forms.
+func (g *ClassGroup) Valid(a math.GroupElement) bool {
+	f, ok := a.(*Form)
+	if !ok || f == nil || f.A == nil || f.B == nil || f.C == nil || f.A.Sign() <= 0 {
+		return false
+	}
+
+	d := new(big.Int).Mul(f.B, f.B)
+	d.Sub(d, new(big.Int).Lsh(new(big.Int).Mul(f.A, f.C), 2))
+	if d.Cmp(g.D) != 0 {
+		return false
+	}
+
+	if f.B.Cmp(new(big.Int).Neg(f.A)) <= 0 || f.B.Cmp(f.A) > 0 || f.A.Cmp(f.C) > 0 {
+		return false
+	}
+
+	return f.A.Cmp(f.C) != 0 || f.B.Sign() >= 0
+}
+
+func (g *ClassGroup) Mul(a, b math.GroupElement) math.GroupElement {
+	if !g.Valid(a) || !g.Valid(b) {
+		return nil
+	}
+
+	res, err := compose(a.(*Form), b.(*Form))
+	if err != nil {
+		return nil
+	}
+
+	return res
+}
+
+func (g *ClassGroup) Exp(a math.GroupElement, k *big.Int) math.GroupElement {
+	if !g.Valid(a) {
+		return nil
+	}
+
+	base := a.(*Form)
+	if k.Sign() < 0 {
+		base = g.Inv(base).(*Form)
+	}
+
+	e := new(big.Int).Abs(k)
+	res := g.Identity().(*Form)
+
+	var err error
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		if res, err = compose(res, res); err != nil {
+			return nil
+		}
+
+		if e.Bit(i) == 1 {
+			if res, err = compose(res, base); err != nil {
+				return nil
+			}
+		}
+	}
+
+	return res
+}
+
+// Inv returns (a, -b, c)
+func (g *ClassGroup) Inv(a math.GroupElement) math.GroupElement {
+	if !g.Valid(a) {
+		return nil
+	}
+
+	f := a.(*Form)
+	return reduce(&Form{A: new(big.Int).Set(f.A), B: new(big.Int).Neg(f.B), C: new(big.Int).Set(f.C)})
+}
+
+// Bytes returns the encoding of the reduced form: len(a) || a || sign(b) || |b|. Reduced forms are unique.
+func (g *ClassGroup) Bytes(a math.GroupElement) []byte {
+	if !g.Valid(a) {
+		return nil
+	}
+
+	f := a.(*Form)
+
+	res := binary.BigEndian.AppendUint32(nil, uint32(len(f.A.Bytes())))
+	res = append(res, f.A.Bytes()...)
+
+	if f.B.Sign() < 0 {
+		res = append(res, 1)
+	} else {
+		res = append(res, 0)
+	}
+
+	return append(res, f.B.Bytes()...)
+}
+
+// compose returns the reduced product of forms with the same discriminant
+func compose(f1, f2 *Form) (*Form, error) {
+	two := big.NewInt(2)
+
+	// g = (b1 + b2)/2, h = (b2 - b1)/2, w = gcd(a1, a2, g)
+	g := new(big.Int).Div(new(big.Int).Add(f1.B, f2.B), two)
+	h := new(big.Int).Div(new(big.Int).Sub(f2.B, f1.B), two)
+	w := new(big.Int).GCD(nil, nil, new(big.Int).GCD(nil, nil, f1.A, f2.A), new(big.Int).Abs(g))
+
+	j := w
+	s := new(big.Int).Div(f1.A, w)
+	t := new(big.Int).Div(f2.A, w)
+	u := new(big.Int).Div(g, w)
+	st := new(big.Int).Mul(s, t)
+
+	// k = (h*u + s*c1) / (t*u) mod s*t
+	tu := new(big.Int).Mul(t, u)
+	k, mu, err := solveMod(tu, new(big.Int).Add(new(big.Int).Mul(h, u), new(big.Int).Mul(s, f1.C)), st)
+	if err != nil {
+		return nil, err
+	}
+
+	n, _, err := solveMod(new(big.Int).Mul(t, mu), new(big.Int).Sub(h, new(big.Int).Mul(t, k)), s)
+	if err != nil {
+		return nil, err
+	}
+
+	k.Add(k, new(big.Int).Mul(mu, n))
+
+	// l = (t*k - h)/s, m = (t*u*k - h*u - s*c1)/(s*t)
+	l := new(big.Int).Div(new(big.Int).Sub(new(big.Int).Mul(t, k), h), s)
+	m := new(big.Int).Mul(tu, k)
+	m.Sub(m, new(big.Int).Mul(h, u))
+	m.Sub(m, new(big.Int).Mul(s, f1.C))
+	m.Div(m, st)
+
+	// a3 = s*t, b3 = j*u - (k*t + l*s), c3 = k*l - j*m
+	b3 := new(big.Int).Mul(j, u)
+	b3.Sub(b3, new(big.Int).Mul(k, t))
+	b3.Sub(b3, new(big.Int).Mul(l, s))
+
+	c3 := new(big.Int).Mul(k, l)
+	c3.Sub(c3, new(big.Int).Mul(j, m))
+
+	return reduce(&Form{A: st, B: b3, C: c3}), nil
+}
+
+// solveMod returns x and mu such that a*x = b (mod m) for all x' = x + mu*n
+func solveMod(a, b, m *big.Int) (x, mu *big.Int, err error) {
+	d := new(big.Int)
+	g := new(big.Int).GCD(d, nil, new(big.Int).Mod(a, m), m)
+
+	q, r := new(big.Int).DivMod(b, g, new(big.Int))
+	if r.Sign() != 0 {
+		return nil, nil, ErrNoSolution
+	}
+
+	x = new(big.Int).Mul(q, d)
+	return x.Mod(x, m), new(big.Int).Div(m, g), nil
+}
+
+// normalize returns the form with -a < b <= a
+func normalize(f *Form) *Form {
+	// r = floor((a - b) / 2a)
+	r := new(big.Int).Div(new(big.Int).Sub(f.A, f.B), new(big.Int).Lsh(f.A, 1))
+
+	// b' = b + 2ra, c' = ar^2 + br + c
+	b := new(big.Int).Add(f.B, new(big.Int).Mul(new(big.Int).Lsh(r, 1), f.A))
+	c := new(big.Int).Mul(new(big.Int).Mul(f.A, r), r)
+	c.Add(c, new(big.Int).Mul(f.B, r))
+	c.Add(c, f.C)
+
+	return &Form{A: new(big.Int).Set(f.A), B: b, C: c}
+}
+
+// reduce returns the unique reduced form: -a < b <= a <= c and b >= 0 if a = c
+func reduce(f *Form) *Form {
+	f = normalize(f)
+
+	for f.A.Cmp(f.C) > 0 || (f.A.Cmp(f.C) == 0 && f.B.Sign() < 0) {
+		// s = floor((c + b) / 2c)
+		s := new(big.Int).Div(new(big.Int).Add(f.C, f.B), new(big.Int).Lsh(f.C, 1))
+
+		// (a, b, c) = (c, -b + 2sc, cs^2 - bs + a)
+		b := new(big.Int).Neg(f.B)
+		b.Add(b, new(big.Int).Mul(new(big.Int).Lsh(s, 1), f.C))
+
+		c := new(big.Int).Mul(new(big.Int).Mul(f.C, s), s)
+		c.Sub(c, new(big.Int).Mul(f.B, s))
+		c.Add(c, f.A)
+
+		f = &Form{A: f.C, B: b, C: c}
+	}
+
+	return normalize(f)
+}
@@ -0,0 +1,177 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsaacc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func checkForm(g *ClassGroup, f *Form) {
+	// b^2 - 4ac = D
+	d := new(big.Int).Mul(f.B, f.B)
+	d.Sub(d, new(big.Int).Lsh(new(big.Int).Mul(f.A, f.C), 2))
+	if d.Cmp(g.D) != 0 {
+		panic("invalid discriminant")
+	}
+
+	// -a < b <= a <= c
+	if f.B.Cmp(new(big.Int).Neg(f.A)) <= 0 || f.B.Cmp(f.A) > 0 || f.A.Cmp(f.C) > 0 {
+		panic("form is not reduced")
+	}
+}
+
+func TestClassGroup(t *testing.T) {
+	g, err := NewClassGroup([]byte("rsaacc class group"), 512)
+	if err != nil {
+		panic(err)
+	}
+
+	if g.D.BitLen() != 512 {
+		panic("invalid discriminant size")
+	}
+
+	same, err := NewClassGroup([]byte("rsaacc class group"), 512)
+	if err != nil || same.D.Cmp(g.D) != 0 {
+		panic("discriminant is not deterministic")
+	}
+
+	equal := func(a, b interface{}) bool {
+		return bytes.Equal(g.Bytes(a), g.Bytes(b))
+	}
+
+	x, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 256))
+	y, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 256))
+
+	gx := g.Exp(g.Generator(), x)
+	gy := g.Exp(g.Generator(), y)
+	checkForm(g, gx.(*Form))
+
+	if !equal(g.Mul(gx, gy), g.Exp(g.Generator(), new(big.Int).Add(x, y))) {
+		panic("g^x * g^y != g^(x+y)")
+	}
+
+	if !equal(g.Exp(gx, y), g.Exp(gy, x)) {
+		panic("(g^x)^y != (g^y)^x")
+	}
+
+	if !equal(g.Mul(gx, g.Inv(gx)), g.Identity()) || !equal(g.Mul(gx, g.Identity()), gx) {
+		panic("invalid identity")
+	}
+
+	if !equal(g.Exp(gx, big.NewInt(-1)), g.Inv(gx)) {
+		panic("invalid negative exponent")
+	}
+
+	if _, err := NewClassGroupFromDiscriminant(big.NewInt(-15)); err != ErrInvalidDiscriminant {
+		panic("invalid discriminant accepted")
+	}
+}
+
+func TestClassGroupInvalidForm(t *testing.T) {
+	g, err := NewClassGroup([]byte("rsaacc class group"), 512)
+	if err != nil {
+		panic(err)
+	}
+
+	gx := g.Exp(g.Generator(), big.NewInt(12345)).(*Form)
+
+	// (a, b + 2a, a + b + c) has the same discriminant but is not reduced
+	shifted := &Form{
+		A: gx.A,
+		B: new(big.Int).Add(gx.B, new(big.Int).Lsh(gx.A, 1)),
+		C: new(big.Int).Add(new(big.Int).Add(gx.A, gx.B), gx.C),
+	}
+
+	invalid := []*Form{
+		{A: big.NewInt(1), B: big.NewInt(2), C: big.NewInt(3)},
+		{A: gx.A, B: gx.B},
+		{A: new(big.Int).Neg(gx.A), B: gx.B, C: new(big.Int).Neg(gx.C)},
+		shifted,
+		nil,
+	}
+
+	if !g.Valid(gx) {
+		panic("valid form is rejected")
+	}
+
+	for _, f := range invalid {
+		if g.Valid(f) || g.Mul(gx, f) != nil || g.Mul(f, gx) != nil || g.Exp(f, big.NewInt(3)) != nil ||
+			g.Inv(f) != nil || g.Bytes(f) != nil {
+			panic("invalid form is accepted")
+		}
+
+		if VerifyMembership(g, f, big.NewInt(3), gx) || VerifyMembership(g, gx, big.NewInt(3), f) {
+			panic("membership is valid for invalid form")
+		}
+	}
+
+	if VerifyMembership(g, gx, big.NewInt(1), gx) {
+		panic("membership is valid for one")
+	}
+}
+
+func TestGroupAccumulator(t *testing.T) {
+	cg, err := NewClassGroup([]byte("rsaacc class group"), 512)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, g := range []Group{NewRSAGroup(RSA2048(), big.NewInt(3)), cg} {
+		values := randPrimes(4)
+		value := Accumulate(g, values...)
+		witness := Accumulate(g, values[1:]...)
+
+		if !VerifyMembership(g, witness, values[0], value) {
+			panic("witness is invalid")
+		}
+
+		if VerifyMembership(g, witness, values[1], value) {
+			panic("witness is valid for another value")
+		}
+
+		// witness of two members proves their product
+		if VerifyMembership(g, Accumulate(g, values[2:]...), product(values[:2]), value) {
+			panic("witness is valid for composite")
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	n := RSA2048()
+	if n.BitLen() != ModulusBits2048 || !bytes.HasSuffix([]byte(n.String()), []byte("2822120720357")) {
+		panic("invalid RSA-2048 modulus")
+	}
+
+	if _, err := GenModulus(1024); err != ErrInvalidModulusSize {
+		panic("small modulus generated")
+	}
+
+	n, trapdoor, err := GenTrapdoor(ModulusBits3072)
+	if err != nil {
+		panic(err)
+	}
+
+	if n.BitLen() != ModulusBits3072 || new(big.Int).Mul(trapdoor.P, trapdoor.Q).Cmp(n) != 0 {
+		panic("invalid modulus")
+	}
+
+	acc := NewAccumulatorWithTrapdoor(trapdoor, big.NewInt(3))
+	x := HashToPrime([]byte("value"))
+	if _, err := acc.Add(x); err != nil {
+		panic(err)
+	}
+
+	trapdoor.Destroy()
+	if trapdoor.P != nil || trapdoor.Q != nil {
+		panic("trapdoor is not destroyed")
+	}
+
+	if _, err := acc.Delete(x); err != ErrNoTrapdoor {
+		panic("value deleted with destroyed trapdoor")
+	}
+}
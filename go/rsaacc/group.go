@@ -0,0 +1,88 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `group.go` defines the group of unknown order shared by RSAGroup and ClassGroup.
+// Only Accumulate and VerifyMembership work over any Group. Accumulator with witness updates,
+// non-membership witnesses, PoE and batch proofs are implemented for the RSA modulus only.
+package rsaacc
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/math"
+)
+
+// Group is the group of unknown order for accumulators: RSAGroup or ClassGroup
+type Group interface {
+	math.Group
+	// Generator returns the fixed element used as the accumulator base
+	Generator() math.GroupElement
+	// Valid checks that the element belongs to the group, so untrusted input can be passed to group operations
+	Valid(a math.GroupElement) bool
+}
+
+// RSAGroup is the multiplicative group Z_N^* with *big.Int elements
+type RSAGroup struct {
+	N, G *big.Int
+}
+
+var _ Group = &RSAGroup{}
+
+// NewRSAGroup returns the group modulo N with the generator G
+func NewRSAGroup(n, g *big.Int) *RSAGroup {
+	return &RSAGroup{N: new(big.Int).Set(n), G: new(big.Int).Set(g)}
+}
+
+func (g *RSAGroup) Generator() math.GroupElement {
+	return new(big.Int).Set(g.G)
+}
+
+// Valid checks that a is in [1, N)
+func (g *RSAGroup) Valid(a math.GroupElement) bool {
+	x, ok := a.(*big.Int)
+	return ok && x != nil && x.Sign() > 0 && x.Cmp(g.N) < 0
+}
+
+func (g *RSAGroup) Identity() math.GroupElement {
+	return big.NewInt(1)
+}
+
+func (g *RSAGroup) Mul(a, b math.GroupElement) math.GroupElement {
+	res := new(big.Int).Mul(a.(*big.Int), b.(*big.Int))
+	return res.Mod(res, g.N)
+}
+
+// Exp panics for negative k if a is not invertible: it means the factor of N is found
+func (g *RSAGroup) Exp(a math.GroupElement, k *big.Int) math.GroupElement {
+	res := new(big.Int).Exp(a.(*big.Int), k, g.N)
+	if res == nil {
+		panic("element is not invertible")
+	}
+
+	return res
+}
+
+func (g *RSAGroup) Inv(a math.GroupElement) math.GroupElement {
+	return g.Exp(a, big.NewInt(-1))
+}
+
+func (g *RSAGroup) Bytes(a math.GroupElement) []byte {
+	return new(big.Int).Mod(a.(*big.Int), g.N).Bytes()
+}
+
+// Accumulate returns Generator^(x_1*...*x_k) in the group
+func Accumulate(g Group, list ...*big.Int) math.GroupElement {
+	return g.Exp(g.Generator(), product(list))
+}
+
+// VerifyMembership checks that witness^x = value in the group for prime x and valid elements
+func VerifyMembership(g Group, witness math.GroupElement, x *big.Int, value math.GroupElement) bool {
+	if x == nil || !math.BailliePSW(x) || !g.Valid(witness) || !g.Valid(value) {
+		return false
+	}
+
+	return bytes.Equal(g.Bytes(g.Exp(witness, x)), g.Bytes(value))
+}
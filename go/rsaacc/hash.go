@@ -0,0 +1,45 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsaacc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/math"
+)
+
+// HashPrimeBits is the size of primes returned by HashToPrime
+const HashPrimeBits = 256
+
+// HashToPrime deterministically maps data to the 256-bit prime:
+// the first prime among SHA256(data || counter) with the top and the lowest bits set for counter = 0, 1, ...
+// Distinct data are not guaranteed to get distinct primes: the top and the lowest bits of the hash are overwritten,
+// so only 254 bits of SHA256 are used. A collision needs about 2^127 hash evaluations,
+// so it is negligible but possible.
+func HashToPrime(data []byte) *big.Int {
+	return hashToPrime(data, HashPrimeBits)
+}
+
+// hashToPrime returns the first prime among the top bits of SHA256(data || counter) with the top and the lowest bits set
+func hashToPrime(data []byte, bits int) *big.Int {
+	buf := make([]byte, len(data)+8)
+	copy(buf, data)
+
+	for counter := uint64(0); ; counter++ {
+		binary.BigEndian.PutUint64(buf[len(data):], counter)
+		h := sha256.Sum256(buf)
+
+		p := new(big.Int).SetBytes(h[:])
+		p.Rsh(p, uint(8*sha256.Size-bits))
+		p.SetBit(p, bits-1, 1)
+		p.SetBit(p, 0, 1)
+
+		if math.BailliePSW(p) {
+			return p
+		}
+	}
+}
@@ -1,27 +1,23 @@
 package rsaacc
 
 import (
-	"crypto/rand"
 	"github.com/olegfomenko/crypto/go/math"
 	"math/big"
 )
 
-const KeySize = 128
+// KeySize is the default modulus size in bytes
+const KeySize = ModulusBits2048 / 8
 
 const Exp = 65537
 
-func mustGetBigPrime(bytes int) *big.Int {
-	res, err := rand.Prime(rand.Reader, bytes*8)
+// Gen generates the modulus of KeySize bytes and discards its factorization
+func Gen() *big.Int {
+	n, err := GenModulus(KeySize * 8)
 	if err != nil {
 		panic(err)
 	}
 
-	return res
-}
-
-func Gen() *big.Int {
-	p, q := mustGetBigPrime(KeySize/2), mustGetBigPrime(KeySize/2)
-	return p.Mul(p, q)
+	return n
 }
 
 func Build(n, g *big.Int, list ...*big.Int) *big.Int {
@@ -59,6 +55,12 @@ func Prove(n, g *big.Int, pos int, list ...*big.Int) *big.Int {
 	return new(big.Int).Exp(g, prod, n)
 }
 
+// Verify checks that witness^value = commit (mod n). Values that are not prime are rejected:
+// for value = 1 the commit itself is a valid witness and the product of members is the member of any their witness.
 func Verify(n, witness, value, commit *big.Int) bool {
+	if value == nil || !math.BailliePSW(value) {
+		return false
+	}
+
 	return new(big.Int).Exp(witness, value, n).Cmp(commit) == 0
 }
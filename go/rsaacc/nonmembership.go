@@ -0,0 +1,85 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `nonmembership.go` implements non-membership witnesses following Li, Li, Xue
+// "Universal Accumulators with Efficient Nonmembership Proofs" (https://eprint.iacr.org/2007/248).
+// For prime x not in the set with product u the Bezout coefficients a*u + b*x = 1 exist,
+// so the witness (a, d = G^-b) satisfies A^a = G^(a*u) = G^(1 - b*x) = d^x * G.
+package rsaacc
+
+import (
+	"math/big"
+
+	"github.com/olegfomenko/crypto/go/math"
+)
+
+// NonMembershipWitness proves that the value is not accumulated: A^A = D^x * G mod N
+type NonMembershipWitness struct {
+	A, D *big.Int
+}
+
+// NonMembershipWitness returns the non-membership witness of the prime value
+func (a *Accumulator) NonMembershipWitness(x *big.Int) (*NonMembershipWitness, error) {
+	if a.Contains(x) {
+		return nil, ErrAlreadyMember
+	}
+
+	list := make([]*big.Int, 0, len(a.members))
+	for _, v := range a.members {
+		list = append(list, v)
+	}
+
+	return ProveNonMembership(a.N, a.G, x, list...)
+}
+
+// ProveNonMembership returns the non-membership witness of the prime value x for the accumulator of the list
+func ProveNonMembership(n, g, x *big.Int, list ...*big.Int) (*NonMembershipWitness, error) {
+	if !math.BailliePSW(x) {
+		return nil, ErrNotPrime
+	}
+
+	u := big.NewInt(1)
+	for _, val := range list {
+		u.Mul(u, val)
+	}
+
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, u, x).Cmp(big.NewInt(1)) != 0 {
+		// x is prime, so x | u
+		return nil, ErrAlreadyMember
+	}
+
+	// a is reduced to [0, x) to keep the witness short: a' = a - kx, b' = b + ku
+	k := new(big.Int).Div(a, x)
+	a.Sub(a, new(big.Int).Mul(k, x))
+	b.Add(b, new(big.Int).Mul(k, u))
+
+	// d = G^-b mod N
+	d := new(big.Int).Exp(g, new(big.Int).Neg(b), n)
+	if d == nil {
+		return nil, ErrNotCoprimeValue
+	}
+
+	return &NonMembershipWitness{A: a, D: d}, nil
+}
+
+// VerifyNonMembership checks that A^a = d^x * G mod N for the accumulator value A
+func VerifyNonMembership(n, g, value, x *big.Int, witness *NonMembershipWitness) bool {
+	if witness == nil || witness.A == nil || witness.D == nil || witness.A.Sign() < 0 || witness.A.Cmp(x) >= 0 {
+		return false
+	}
+
+	// a = 0 would mean x | 1
+	if witness.A.Sign() == 0 {
+		return false
+	}
+
+	left := new(big.Int).Exp(value, witness.A, n)
+
+	right := new(big.Int).Exp(witness.D, x, n)
+	right.Mul(right, g).Mod(right, n)
+
+	return left.Cmp(right) == 0
+}
@@ -0,0 +1,68 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsaacc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestHashToPrime(t *testing.T) {
+	p := HashToPrime([]byte("credential-1"))
+	if p.BitLen() != HashPrimeBits || !p.ProbablyPrime(20) {
+		panic("invalid prime")
+	}
+
+	if p.Cmp(HashToPrime([]byte("credential-1"))) != 0 {
+		panic("hash to prime is not deterministic")
+	}
+
+	if p.Cmp(HashToPrime([]byte("credential-2"))) == 0 {
+		panic("different data mapped to the same prime")
+	}
+}
+
+func TestNonMembership(t *testing.T) {
+	acc := NewAccumulator(Gen(), big.NewInt(3))
+
+	revoked := []*big.Int{HashToPrime([]byte("id-1")), HashToPrime([]byte("id-2")), HashToPrime([]byte("id-3"))}
+	for _, x := range revoked {
+		if _, err := acc.Add(x); err != nil {
+			panic(err)
+		}
+	}
+
+	x := HashToPrime([]byte("id-4"))
+
+	witness, err := acc.NonMembershipWitness(x)
+	if err != nil {
+		panic(err)
+	}
+
+	if !VerifyNonMembership(acc.N, acc.G, acc.Value, x, witness) {
+		panic("non-membership witness is invalid")
+	}
+
+	if VerifyNonMembership(acc.N, acc.G, acc.Value, revoked[0], witness) {
+		panic("non-membership witness is valid for the member")
+	}
+
+	if _, err := acc.NonMembershipWitness(revoked[1]); err != ErrAlreadyMember {
+		panic("non-membership witness returned for the member")
+	}
+
+	if _, err := ProveNonMembership(acc.N, acc.G, x, x, revoked[0]); err != ErrAlreadyMember {
+		panic("non-membership witness returned for the member")
+	}
+
+	// witness is not valid after x is added
+	if _, err := acc.Add(x); err != nil {
+		panic(err)
+	}
+
+	if VerifyNonMembership(acc.N, acc.G, acc.Value, x, witness) {
+		panic("non-membership witness is valid after addition")
+	}
+}
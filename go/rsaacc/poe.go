@@ -0,0 +1,115 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `poe.go` implements non-interactive proofs of exponentiation in the RSA group from
+// Boneh, Bünz, Fisch "Batching Techniques for Accumulators with Applications to IOPs and Stateless Blockchains"
+// (https://eprint.iacr.org/2018/1188):
+//   - PoE (Wesolowski): proves u^x = w, verifier does two exponentiations with 128-bit exponents and x mod l
+//   - PoKE2: proves knowledge of x such that u^x = w without revealing x
+//
+// The challenges are derived with Fiat-Shamir transform using hash-to-prime.
+package rsaacc
+
+import (
+	"crypto/sha256"
+	"math/big"
+)
+
+// ChallengeBits is the size of the prime challenge l
+const ChallengeBits = 128
+
+// PoEProof proves u^x = w: Q = u^(x / l)
+type PoEProof struct {
+	Q *big.Int
+}
+
+// PoKEProof proves knowledge of x such that u^x = w: z = g^x, Q = (u*g^alpha)^(x / l), r = x mod l
+type PoKEProof struct {
+	Z, Q, R *big.Int
+}
+
+// ProvePoE returns the proof of u^x = w mod n
+func ProvePoE(n, u, x, w *big.Int) *PoEProof {
+	l := challenge(n, u, x, w)
+	return &PoEProof{Q: new(big.Int).Exp(u, new(big.Int).Div(x, l), n)}
+}
+
+// VerifyPoE checks that Q^l * u^(x mod l) = w mod n
+func VerifyPoE(n, u, x, w *big.Int, proof *PoEProof) bool {
+	if proof == nil || proof.Q == nil {
+		return false
+	}
+
+	l := challenge(n, u, x, w)
+	r := new(big.Int).Mod(x, l)
+
+	res := new(big.Int).Exp(proof.Q, l, n)
+	res.Mul(res, new(big.Int).Exp(u, r, n)).Mod(res, n)
+
+	return res.Cmp(new(big.Int).Mod(w, n)) == 0
+}
+
+// ProvePoKE returns the proof of knowledge of x > 0 such that u^x = w mod n. The generator g should be
+// the same for prover and verifier and should not be chosen by the prover.
+func ProvePoKE(n, g, u, x, w *big.Int) *PoKEProof {
+	z := new(big.Int).Exp(g, x, n)
+	l := challenge(n, u, w, z)
+	alpha := challengeInt(n, u, w, z, l)
+
+	// base = u * g^alpha
+	base := new(big.Int).Exp(g, alpha, n)
+	base.Mul(base, u).Mod(base, n)
+
+	q, r := new(big.Int).QuoRem(x, l, new(big.Int))
+	return &PoKEProof{Z: z, Q: new(big.Int).Exp(base, q, n), R: r}
+}
+
+// VerifyPoKE checks that r < l and Q^l * (u*g^alpha)^r = w * z^alpha mod n
+func VerifyPoKE(n, g, u, w *big.Int, proof *PoKEProof) bool {
+	if proof == nil || proof.Z == nil || proof.Q == nil || proof.R == nil {
+		return false
+	}
+
+	l := challenge(n, u, w, proof.Z)
+	alpha := challengeInt(n, u, w, proof.Z, l)
+
+	if proof.R.Sign() < 0 || proof.R.Cmp(l) >= 0 {
+		return false
+	}
+
+	base := new(big.Int).Exp(g, alpha, n)
+	base.Mul(base, u).Mod(base, n)
+
+	left := new(big.Int).Exp(proof.Q, l, n)
+	left.Mul(left, new(big.Int).Exp(base, proof.R, n)).Mod(left, n)
+
+	right := new(big.Int).Exp(proof.Z, alpha, n)
+	right.Mul(right, w).Mod(right, n)
+
+	return left.Cmp(right) == 0
+}
+
+// challenge returns the 128-bit prime hash of the values
+func challenge(values ...*big.Int) *big.Int {
+	return hashToPrime(hashValues(values...), ChallengeBits)
+}
+
+// challengeInt returns the 128-bit integer hash of the values
+func challengeInt(values ...*big.Int) *big.Int {
+	h := sha256.Sum256(hashValues(values...))
+	return new(big.Int).SetBytes(h[:ChallengeBits/8])
+}
+
+// hashValues returns the length-prefixed encoding of the values
+func hashValues(values ...*big.Int) []byte {
+	var res []byte
+	for _, v := range values {
+		b := v.Bytes()
+		res = append(res, big.NewInt(int64(len(b))).FillBytes(make([]byte, 8))...)
+		res = append(res, b...)
+	}
+
+	return res
+}
@@ -0,0 +1,52 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package rsaacc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestPoE(t *testing.T) {
+	n := Gen()
+	u := big.NewInt(5)
+	x, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 2048))
+	w := new(big.Int).Exp(u, x, n)
+
+	proof := ProvePoE(n, u, x, w)
+	if !VerifyPoE(n, u, x, w, proof) {
+		panic("proof is invalid")
+	}
+
+	if VerifyPoE(n, u, new(big.Int).Add(x, big.NewInt(1)), w, proof) {
+		panic("proof is valid for another exponent")
+	}
+
+	if VerifyPoE(n, u, x, new(big.Int).Add(w, big.NewInt(1)), proof) {
+		panic("proof is valid for another result")
+	}
+}
+
+func TestPoKE(t *testing.T) {
+	n := Gen()
+	g, u := big.NewInt(3), big.NewInt(5)
+	x, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 2048))
+	w := new(big.Int).Exp(u, x, n)
+
+	proof := ProvePoKE(n, g, u, x, w)
+	if !VerifyPoKE(n, g, u, w, proof) {
+		panic("proof is invalid")
+	}
+
+	if VerifyPoKE(n, g, u, new(big.Int).Add(w, big.NewInt(1)), proof) {
+		panic("proof is valid for another result")
+	}
+
+	proof.R.Add(proof.R, big.NewInt(1))
+	if VerifyPoKE(n, g, u, w, proof) {
+		panic("modified proof is valid")
+	}
+}
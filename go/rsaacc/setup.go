@@ -0,0 +1,96 @@
+// Package rsaacc
+// Copyright 2023 Oleg Fomenko. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// File `setup.go` contains the options of unknown-order group setup:
+//   - GenTrapdoor: trusted setup, the manager keeps the factorization to delete values without witnesses
+//   - GenModulus: trusted setup, the factorization is destroyed right after generation
+//   - RSA2048: public RSA-2048 challenge modulus which factorization is believed to be unknown
+//   - NewClassGroup: class group of imaginary quadratic order that requires no trusted setup at all,
+//     supported by Accumulate and VerifyMembership only
+package rsaacc
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+const (
+	ModulusBits2048 = 2048
+	ModulusBits3072 = 3072
+)
+
+var ErrInvalidModulusSize = errors.New("modulus size should be even and at least 2048 bits")
+
+// rsa2048 is the RSA-2048 number from RSA Factoring Challenge
+// More information: https://en.wikipedia.org/wiki/RSA_numbers#RSA-2048
+const rsa2048 = "C7970CEEDCC3B0754490201A7AA613CD73911081C790F5F1A8726F463550BB5B7FF0DB8E1EA1189EC72F93D1650011BD" +
+	"721AEEACC2ACDE32A04107F0648C2813A31F5B0B7765FF8B44B4B6FFC93384B646EB09C7CF5E8592D40EA33C80039F35B4F14A04B51F7BFD" +
+	"781BE4D1673164BA8EB991C2C4D730BBBE35F592BDEF524AF7E8DAEFD26C66FC02C479AF89D64D373F442709439DE66CEB955F3EA37D5159F6" +
+	"135809F85334B5CB1813ADDC80CD05609F10AC6A95AD65872C909525BDAD32BC729592642920F24C61DC5B3C3B7923E56B16A4D9D373D8721F" +
+	"24A3FC0F1B3131F55615172866BCCC30F95054C824E733A5EB6817F7BC16399D48C6361CC7E5"
+
+// RSA2048 returns the RSA-2048 challenge modulus. Nobody knows its factorization, so it can be used as a public
+// trapdoor-free setup. Values can be deleted only with DeleteWithWitness.
+func RSA2048() *big.Int {
+	n, _ := new(big.Int).SetString(rsa2048, 16)
+	return n
+}
+
+// GenModulus generates the modulus N = P*Q of the given size and destroys its factorization
+func GenModulus(bits int) (*big.Int, error) {
+	n, t, err := GenTrapdoor(bits)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Destroy()
+	return n, nil
+}
+
+// GenTrapdoor generates the modulus N = P*Q of the given size and returns its factorization.
+// The trapdoor should be destroyed with Trapdoor.Destroy when it is not needed anymore.
+func GenTrapdoor(bits int) (*big.Int, *Trapdoor, error) {
+	if bits < ModulusBits2048 || bits%2 != 0 {
+		return nil, nil, ErrInvalidModulusSize
+	}
+
+	for {
+		// rand.Prime sets two top bits, so N has exactly the given size
+		p, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		q, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		return new(big.Int).Mul(p, q), &Trapdoor{P: p, Q: q}, nil
+	}
+}
+
+// Destroy overwrites the factorization in memory
+func (t *Trapdoor) Destroy() {
+	for _, v := range []*big.Int{t.P, t.Q} {
+		if v == nil {
+			continue
+		}
+
+		words := v.Bits()
+		for i := range words {
+			words[i] = 0
+		}
+
+		v.SetInt64(0)
+	}
+
+	t.P, t.Q = nil, nil
+}
@@ -0,0 +1,115 @@
+package transparency_log
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/bits"
+)
+
+// Domain separation prefixes from RFC 6962 section 2.1
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var (
+	ErrIndexOutOfRange = errors.New("leaf index should be less than tree size")
+	ErrInvalidSize     = errors.New("tree size should not exceed log size")
+	ErrInvalidRange    = errors.New("first tree size should not exceed second tree size")
+)
+
+type ILog interface {
+	Append(data []byte) uint64
+	Size() uint64
+	Root() []byte
+	RootAt(size uint64) ([]byte, error)
+	InclusionProof(index, size uint64) ([][]byte, error)
+	ConsistencyProof(size1, size2 uint64) ([][]byte, error)
+}
+
+// Log is the append-only Merkle log defined in RFC 6962 (Certificate Transparency).
+// Unlike Treap, leaves are ordered by the insertion order and can not be removed,
+// so every earlier tree is a prefix of the current one.
+type Log struct {
+	leaves [][]byte
+}
+
+var _ ILog = &Log{}
+
+func New() *Log {
+	return &Log{}
+}
+
+// LeafHash is SHA-256(0x00 || data)
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// NodeHash is SHA-256(0x01 || left || right)
+func NodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// EmptyRoot is the root of the tree without leaves: SHA-256 of the empty string
+func EmptyRoot() []byte {
+	h := sha256.Sum256(nil)
+	return h[:]
+}
+
+// Append adds the leaf to the end of the log and returns its index
+func (l *Log) Append(data []byte) uint64 {
+	l.leaves = append(l.leaves, LeafHash(data))
+	return uint64(len(l.leaves) - 1)
+}
+
+func (l *Log) Size() uint64 {
+	return uint64(len(l.leaves))
+}
+
+// LeafHashAt returns the stored hash of the leaf with given index
+func (l *Log) LeafHashAt(index uint64) ([]byte, error) {
+	if index >= l.Size() {
+		return nil, ErrIndexOutOfRange
+	}
+
+	return l.leaves[index], nil
+}
+
+// Root returns the root of the whole log
+func (l *Log) Root() []byte {
+	return mth(l.leaves)
+}
+
+// RootAt returns the root of the tree formed by the first size leaves
+func (l *Log) RootAt(size uint64) ([]byte, error) {
+	if size > l.Size() {
+		return nil, ErrInvalidSize
+	}
+
+	return mth(l.leaves[:size]), nil
+}
+
+// mth is the Merkle Tree Hash over the list of leaf hashes (RFC 6962 section 2.1)
+func mth(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return EmptyRoot()
+	case 1:
+		return leaves[0]
+	}
+
+	k := split(uint64(len(leaves)))
+	return NodeHash(mth(leaves[:k]), mth(leaves[k:]))
+}
+
+// split returns the largest power of two strictly less than n, n > 1
+func split(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
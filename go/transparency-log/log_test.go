@@ -0,0 +1,290 @@
+package transparency_log
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors from the RFC 6962 reference implementation (certificate-transparency, transparency-dev/merkle)
+var (
+	testLeaves = []string{
+		"",
+		"00",
+		"10",
+		"2021",
+		"3031",
+		"40414243",
+		"5051525354555657",
+		"606162636465666768696a6b6c6d6e6f",
+	}
+
+	// testRoots are indexed by tree size
+	testRoots = []string{
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+
+	testInclusion = []struct {
+		index, size uint64
+		proof       []string
+	}{
+		{0, 1, nil},
+		{0, 8, []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{5, 8, []string{
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 3, []string{
+			"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		}},
+		{1, 5, []string{
+			"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		}},
+	}
+
+	testConsistency = []struct {
+		size1, size2 uint64
+		proof        []string
+	}{
+		{1, 1, nil},
+		{1, 8, []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{6, 8, []string{
+			"0ebc5d3437fbe2db158b9f126a1d118e308181031d0a949f8dededebc558ef6a",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 5, []string{
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		}},
+		{6, 7, []string{
+			"0ebc5d3437fbe2db158b9f126a1d118e308181031d0a949f8dededebc558ef6a",
+			"b08693ec2e721597130641e8211e7eedccb4c26413963eee6c1e2ed16ffb1a5f",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+	}
+)
+
+func hd(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func hds(s []string) [][]byte {
+	res := make([][]byte, 0, len(s))
+	for _, v := range s {
+		res = append(res, hd(v))
+	}
+	return res
+}
+
+func equalProofs(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testLog() *Log {
+	l := New()
+	for _, leaf := range testLeaves {
+		l.Append(hd(leaf))
+	}
+	return l
+}
+
+func TestRoots(t *testing.T) {
+	l := New()
+	if !bytes.Equal(l.Root(), hd(testRoots[0])) {
+		panic("invalid empty root")
+	}
+
+	for i, leaf := range testLeaves {
+		if index := l.Append(hd(leaf)); index != uint64(i) {
+			panic("invalid leaf index")
+		}
+
+		if !bytes.Equal(l.Root(), hd(testRoots[i+1])) {
+			panic("invalid root")
+		}
+	}
+
+	for size := range testRoots {
+		root, err := l.RootAt(uint64(size))
+		if err != nil {
+			panic(err)
+		}
+
+		if !bytes.Equal(root, hd(testRoots[size])) {
+			panic("invalid root at size")
+		}
+	}
+
+	if _, err := l.RootAt(l.Size() + 1); err != ErrInvalidSize {
+		panic("root of the tree bigger than log")
+	}
+}
+
+func TestInclusionProof(t *testing.T) {
+	l := testLog()
+
+	for _, v := range testInclusion {
+		proof, err := l.InclusionProof(v.index, v.size)
+		if err != nil {
+			panic(err)
+		}
+
+		if !equalProofs(proof, hds(v.proof)) {
+			panic("inclusion proof does not match test vector")
+		}
+
+		leaf := LeafHash(hd(testLeaves[v.index]))
+		root := hd(testRoots[v.size])
+		if !VerifyInclusion(leaf, v.index, v.size, proof, root) {
+			panic("inclusion proof is invalid")
+		}
+
+		if VerifyInclusion(leaf, v.index+1, v.size, proof, root) {
+			panic("inclusion proof is valid for another index")
+		}
+
+		if VerifyInclusion(leaf, v.index, v.size*2, proof, root) {
+			panic("inclusion proof is valid for another size")
+		}
+
+		if VerifyInclusion(LeafHash([]byte("wrong")), v.index, v.size, proof, root) {
+			panic("inclusion proof is valid for another leaf")
+		}
+
+		if VerifyInclusion(leaf, v.index, v.size, append(proof, root), root) {
+			panic("inclusion proof with trailing hash is valid")
+		}
+
+		for i := range proof {
+			wrong := hds(v.proof)
+			wrong[i][0] ^= 8
+			if VerifyInclusion(leaf, v.index, v.size, wrong, root) {
+				panic("modified inclusion proof is valid")
+			}
+		}
+	}
+
+	// All leaves of all trees
+	for size := uint64(1); size <= l.Size(); size++ {
+		root, _ := l.RootAt(size)
+		for index := uint64(0); index < size; index++ {
+			proof, err := l.InclusionProof(index, size)
+			if err != nil {
+				panic(err)
+			}
+
+			leaf, _ := l.LeafHashAt(index)
+			if !VerifyInclusion(leaf, index, size, proof, root) {
+				panic("inclusion proof is invalid")
+			}
+		}
+	}
+
+	if _, err := l.InclusionProof(3, 3); err != ErrIndexOutOfRange {
+		panic("inclusion proof for index out of tree")
+	}
+}
+
+func TestConsistencyProof(t *testing.T) {
+	l := testLog()
+
+	for _, v := range testConsistency {
+		proof, err := l.ConsistencyProof(v.size1, v.size2)
+		if err != nil {
+			panic(err)
+		}
+
+		if !equalProofs(proof, hds(v.proof)) {
+			panic("consistency proof does not match test vector")
+		}
+
+		root1, root2 := hd(testRoots[v.size1]), hd(testRoots[v.size2])
+		if !VerifyConsistency(v.size1, v.size2, root1, root2, proof) {
+			panic("consistency proof is invalid")
+		}
+
+		if v.size1 == v.size2 {
+			continue
+		}
+
+		if VerifyConsistency(v.size1, v.size2, root2, root1, proof) {
+			panic("consistency proof is valid for swapped roots")
+		}
+
+		if VerifyConsistency(v.size1+1, v.size2, root1, root2, proof) {
+			panic("consistency proof is valid for another size")
+		}
+
+		if VerifyConsistency(v.size1, v.size2, root1, root2, nil) {
+			panic("empty consistency proof is valid")
+		}
+
+		for i := range proof {
+			wrong := hds(v.proof)
+			wrong[i][0] ^= 8
+			if VerifyConsistency(v.size1, v.size2, root1, root2, wrong) {
+				panic("modified consistency proof is valid")
+			}
+		}
+	}
+
+	// All pairs of trees
+	for size2 := uint64(0); size2 <= l.Size(); size2++ {
+		root2, _ := l.RootAt(size2)
+		for size1 := uint64(0); size1 <= size2; size1++ {
+			root1, _ := l.RootAt(size1)
+			proof, err := l.ConsistencyProof(size1, size2)
+			if err != nil {
+				panic(err)
+			}
+
+			if !VerifyConsistency(size1, size2, root1, root2, proof) {
+				panic("consistency proof is invalid")
+			}
+		}
+	}
+
+	// bogus root of the empty tree
+	root, _ := l.RootAt(l.Size())
+	if VerifyConsistency(0, l.Size(), root, root, nil) || VerifyConsistency(0, 0, root, root, nil) {
+		panic("consistency proof is valid for invalid empty root")
+	}
+
+	if _, err := l.ConsistencyProof(5, 4); err != ErrInvalidRange {
+		panic("consistency proof for decreasing sizes")
+	}
+}
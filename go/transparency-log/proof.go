@@ -0,0 +1,150 @@
+package transparency_log
+
+import (
+	"bytes"
+)
+
+// InclusionProof returns the audit path of the leaf in the tree of given size (RFC 6962 section 2.1.1)
+func (l *Log) InclusionProof(index, size uint64) ([][]byte, error) {
+	if size > l.Size() {
+		return nil, ErrInvalidSize
+	}
+
+	if index >= size {
+		return nil, ErrIndexOutOfRange
+	}
+
+	return path(index, l.leaves[:size]), nil
+}
+
+func path(m uint64, leaves [][]byte) [][]byte {
+	n := uint64(len(leaves))
+	if n <= 1 {
+		return nil
+	}
+
+	k := split(n)
+	if m < k {
+		return append(path(m, leaves[:k]), mth(leaves[k:]))
+	}
+
+	return append(path(m-k, leaves[k:]), mth(leaves[:k]))
+}
+
+// ConsistencyProof returns the proof that the tree of size1 is a prefix of the tree of size2 (RFC 6962 section 2.1.2).
+// The proof is empty if size1 is zero or equal to size2.
+func (l *Log) ConsistencyProof(size1, size2 uint64) ([][]byte, error) {
+	if size2 > l.Size() {
+		return nil, ErrInvalidSize
+	}
+
+	if size1 > size2 {
+		return nil, ErrInvalidRange
+	}
+
+	if size1 == 0 || size1 == size2 {
+		return nil, nil
+	}
+
+	return subproof(size1, l.leaves[:size2], true), nil
+}
+
+func subproof(m uint64, leaves [][]byte, complete bool) [][]byte {
+	n := uint64(len(leaves))
+	if m == n {
+		if complete {
+			return nil
+		}
+
+		return [][]byte{mth(leaves)}
+	}
+
+	k := split(n)
+	if m <= k {
+		return append(subproof(m, leaves[:k], complete), mth(leaves[k:]))
+	}
+
+	return append(subproof(m-k, leaves[k:], false), mth(leaves[:k]))
+}
+
+// VerifyInclusion checks the audit path of the leaf hash against the root of the tree of given size.
+// Implements the algorithm from RFC 9162 section 2.1.3.2.
+func VerifyInclusion(leafHash []byte, index, size uint64, proof [][]byte, root []byte) bool {
+	if index >= size {
+		return false
+	}
+
+	fn, sn := index, size-1
+	r := leafHash
+
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+// VerifyConsistency checks that the tree with root1 of size1 is a prefix of the tree with root2 of size2.
+// Implements the algorithm from RFC 9162 section 2.1.4.2.
+func VerifyConsistency(size1, size2 uint64, root1, root2 []byte, proof [][]byte) bool {
+	switch {
+	case size1 > size2:
+		return false
+	case size1 == 0:
+		// Empty tree is a prefix of any tree, but its root is fixed
+		return len(proof) == 0 && bytes.Equal(root1, EmptyRoot()) && (size2 > 0 || bytes.Equal(root2, root1))
+	case size1 == size2:
+		return len(proof) == 0 && bytes.Equal(root1, root2)
+	case len(proof) == 0:
+		return false
+	}
+
+	// If size1 is a power of two, the old root is the first node of the path and is omitted from the proof
+	if size1&(size1-1) == 0 {
+		proof = append([][]byte{root1}, proof...)
+	}
+
+	fn, sn := size1-1, size2-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return sn == 0 && bytes.Equal(fr, root1) && bytes.Equal(sr, root2)
+}
@@ -0,0 +1,89 @@
+package transparency_log
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/cloudflare/bn256"
+	schnorr "github.com/olegfomenko/crypto/go/schnorr-bn256"
+)
+
+const (
+	// sthVersion is v1 from RFC 6962 section 3.2
+	sthVersion = 0
+	// sthSignatureType is tree_hash from RFC 6962 section 3.2
+	sthSignatureType = 1
+)
+
+var ErrInvalidRoot = errors.New("root should be 32 bytes")
+
+// SignedTreeHead is the log commitment to its size and root (RFC 6962 section 3.5)
+// signed with Schnorr signature over bn256.
+type SignedTreeHead struct {
+	TreeSize  uint64
+	Timestamp uint64
+	RootHash  []byte
+	Signature *schnorr.SchnorrSignature
+}
+
+// Signer keeps the log key pair. Public should be Private*G.
+type Signer struct {
+	Private *big.Int
+	Public  *bn256.G1
+	G       *bn256.G1
+}
+
+// NewSigner generates the random key pair over the base point G
+func NewSigner(G *bn256.G1) (*Signer, error) {
+	prv, pub, err := schnorr.R(G)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{Private: prv, Public: pub, G: G}, nil
+}
+
+// Sign creates the signed tree head for the tree of given size and root.
+// Timestamp is milliseconds since the epoch as in RFC 6962.
+func (s *Signer) Sign(size, timestamp uint64, root []byte) (*SignedTreeHead, error) {
+	if len(root) != 32 {
+		return nil, ErrInvalidRoot
+	}
+
+	sig, err := schnorr.SignSchnorr(s.Private, s.Public, s.G, treeHeadMsg(size, timestamp, root))
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedTreeHead{
+		TreeSize:  size,
+		Timestamp: timestamp,
+		RootHash:  root,
+		Signature: sig,
+	}, nil
+}
+
+// SignTreeHead signs the current state of the log
+func (l *Log) SignTreeHead(s *Signer, timestamp uint64) (*SignedTreeHead, error) {
+	return s.Sign(l.Size(), timestamp, l.Root())
+}
+
+// VerifyTreeHead verifies the tree head signature with log public key
+func VerifyTreeHead(sth *SignedTreeHead, PublicKey *bn256.G1, G *bn256.G1) bool {
+	if sth == nil || sth.Signature == nil || len(sth.RootHash) != 32 {
+		return false
+	}
+
+	return schnorr.VerifySchnorr(sth.Signature, PublicKey, G, treeHeadMsg(sth.TreeSize, sth.Timestamp, sth.RootHash))
+}
+
+// treeHeadMsg hashes the TreeHeadSignature structure: version || signature_type || timestamp || tree_size || root
+func treeHeadMsg(size, timestamp uint64, root []byte) *big.Int {
+	buf := make([]byte, 0, 2+8+8+len(root))
+	buf = append(buf, sthVersion, sthSignatureType)
+	buf = binary.BigEndian.AppendUint64(buf, timestamp)
+	buf = binary.BigEndian.AppendUint64(buf, size)
+	buf = append(buf, root...)
+	return schnorr.Msg(buf)
+}
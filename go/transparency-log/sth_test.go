@@ -0,0 +1,60 @@
+package transparency_log
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/cloudflare/bn256"
+)
+
+func TestSignedTreeHead(t *testing.T) {
+	_, G, err := bn256.RandomG1(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	signer, err := NewSigner(G)
+	if err != nil {
+		panic(err)
+	}
+
+	l := testLog()
+	sth, err := l.SignTreeHead(signer, 1700000000000)
+	if err != nil {
+		panic(err)
+	}
+
+	if !VerifyTreeHead(sth, signer.Public, G) {
+		panic("signed tree head is invalid")
+	}
+
+	other, err := NewSigner(G)
+	if err != nil {
+		panic(err)
+	}
+
+	if VerifyTreeHead(sth, other.Public, G) {
+		panic("signed tree head is valid for another key")
+	}
+
+	sth.TreeSize--
+	if VerifyTreeHead(sth, signer.Public, G) {
+		panic("signed tree head is valid for another size")
+	}
+
+	sth.TreeSize++
+	sth.Timestamp++
+	if VerifyTreeHead(sth, signer.Public, G) {
+		panic("signed tree head is valid for another timestamp")
+	}
+
+	sth.Timestamp--
+	sth.RootHash = EmptyRoot()
+	if VerifyTreeHead(sth, signer.Public, G) {
+		panic("signed tree head is valid for another root")
+	}
+
+	if _, err := signer.Sign(1, 0, []byte("short")); err != ErrInvalidRoot {
+		panic("signed tree head with invalid root")
+	}
+}